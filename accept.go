@@ -0,0 +1,189 @@
+package render
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// AcceptNegotiater implement `Negotiater` according to the `Accept` header(RFC 9110 section 12.5.1),
+// it supports q-values, wildcards(`*/*`, `application/*`), media-type parameters and specificity,
+// the `charset` parameter of content types is ignored when matching.
+//
+// If several content types are accepted with the same quality, the first one in `ctypes` wins,
+// i.e. the order of `ctypes` is the server preference order.
+type AcceptNegotiater struct{}
+
+// Negotiate implement `Negotiater`
+func (n AcceptNegotiater) Negotiate(acceptHeader string, ctypes ...string) (ctype string, err error) {
+	if len(ctypes) == 0 {
+		return "", fmt.Errorf("no content type offered for accept %q", acceptHeader)
+	}
+	ranges := ParseAccept(acceptHeader)
+	if len(ranges) == 0 {
+		return ctypes[0], nil
+	}
+	bestQ := 0.0
+	for _, ct := range ctypes {
+		q := ranges.Quality(ParseMediaType(ct))
+		if q > bestQ {
+			ctype, bestQ = ct, q
+		}
+	}
+	if ctype == "" {
+		return "", fmt.Errorf("no content type in %v acceptable for %q", ctypes, acceptHeader)
+	}
+	return ctype, nil
+}
+
+// MediaType defines the parsed media type, e.g. `application/json; charset=utf-8`
+type MediaType struct {
+	Type    string
+	Subtype string
+	Params  map[string]string
+}
+
+// ParseMediaType parses the media type, type, subtype and parameter names are lower cased,
+// unlike `mime.ParseMediaType`, wildcards are allowed
+func ParseMediaType(s string) MediaType {
+	mt := MediaType{}
+	parts := strings.Split(s, ";")
+	full := strings.ToLower(strings.TrimSpace(parts[0]))
+	if i := strings.IndexByte(full, '/'); i >= 0 {
+		mt.Type, mt.Subtype = full[:i], full[i+1:]
+	} else {
+		mt.Type, mt.Subtype = full, "*"
+	}
+	for _, p := range parts[1:] {
+		k, v, _ := strings.Cut(p, "=")
+		k = strings.ToLower(strings.TrimSpace(k))
+		if k == "" {
+			continue
+		}
+		if mt.Params == nil {
+			mt.Params = make(map[string]string)
+		}
+		mt.Params[k] = strings.Trim(strings.TrimSpace(v), `"`)
+	}
+	return mt
+}
+
+// String returns `type/subtype` without parameters
+func (mt MediaType) String() string {
+	return mt.Type + "/" + mt.Subtype
+}
+
+// AcceptRange defines one media range of `Accept` header
+type AcceptRange struct {
+	MediaType
+	Q float64
+}
+
+// Match tells if the media range matches mt, and returns the specificity of the match:
+// 1 for `*/*`, 2 for `type/*`, 3 for `type/subtype`, 4 for `type/subtype` with parameters.
+// The `charset` parameter is ignored.
+func (ar AcceptRange) Match(mt MediaType) (int, bool) {
+	switch {
+	case ar.Type == "*" && ar.Subtype == "*":
+		return 1, true
+	case ar.Type != mt.Type:
+		return 0, false
+	case ar.Subtype == "*":
+		return 2, true
+	case ar.Subtype != mt.Subtype:
+		return 0, false
+	}
+	specificity := 3
+	for k, v := range ar.Params {
+		if k == "charset" {
+			continue
+		}
+		if !strings.EqualFold(mt.Params[k], v) {
+			return 0, false
+		}
+		specificity = 4
+	}
+	return specificity, true
+}
+
+// AcceptRanges defines the parsed `Accept` header
+type AcceptRanges []AcceptRange
+
+// ParseAccept parses the `Accept` header, invalid q-values are treated as 0
+func ParseAccept(header string) AcceptRanges {
+	var ranges AcceptRanges
+	for _, item := range strings.Split(header, ",") {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+		ar := AcceptRange{Q: 1}
+		// NOTE: parameters after `q` are accept extensions, not media type parameters
+		mtPart, qPart, hasQ := cutQ(item)
+		ar.MediaType = ParseMediaType(mtPart)
+		if hasQ {
+			q, err := strconv.ParseFloat(strings.TrimSpace(qPart), 64)
+			if err != nil || q < 0 || q > 1 {
+				q = 0
+			}
+			ar.Q = q
+		}
+		ranges = append(ranges, ar)
+	}
+	return ranges
+}
+
+// Quality returns the q-value of the most specific media range which matches mt, 0 means not acceptable
+func (ars AcceptRanges) Quality(mt MediaType) float64 {
+	best, q := 0, 0.0
+	for _, ar := range ars {
+		if specificity, ok := ar.Match(mt); ok && specificity > best {
+			best, q = specificity, ar.Q
+		}
+	}
+	return q
+}
+
+func cutQ(item string) (mediaType, q string, ok bool) {
+	parts := strings.Split(item, ";")
+	for i, p := range parts[1:] {
+		k, v, _ := strings.Cut(p, "=")
+		if strings.EqualFold(strings.TrimSpace(k), "q") {
+			return strings.Join(parts[:i+1], ";"), v, true
+		}
+	}
+	return item, "", false
+}
+
+// SortContentTypes sorts content types by server preference: default render first,
+// then the builtin content types in declaration order, then others in lexical order
+func SortContentTypes(cts []ContentType) {
+	sortContentTypes(cts, defaultRenderer.DefaultContentType())
+}
+
+func sortContentTypes(cts []ContentType, defaultContentType ContentType) {
+	rank := func(ct ContentType) int {
+		if ct == defaultContentType {
+			return -1
+		}
+		for i, p := range preferences {
+			if p == ct {
+				return i
+			}
+		}
+		return len(preferences)
+	}
+	sort.SliceStable(cts, func(i, j int) bool {
+		ri, rj := rank(cts[i]), rank(cts[j])
+		if ri != rj {
+			return ri < rj
+		}
+		return cts[i] < cts[j]
+	})
+}
+
+var preferences = []ContentType{JSON, JSONASCII, JSONP, HTML, Text, PROTOBUF, Binary, YAML, TOML, MSGPACK, XML, XHTML, ProblemJSON, NDJSON, EventStream}
+
+var (
+	_ Negotiater = AcceptNegotiater{}
+)
@@ -0,0 +1,51 @@
+package render_test
+
+import (
+	"testing"
+
+	"github.com/ccmonky/render"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAccept(t *testing.T) {
+	ranges := render.ParseAccept("text/html;level=1;q=0.5;ext=x, application/*;q=0.2, */*;q=abc, text/plain")
+	assert.Equalf(t, 4, len(ranges), "ranges")
+	assert.Equalf(t, "text/html", ranges[0].String(), "media type")
+	assert.Equalf(t, map[string]string{"level": "1"}, ranges[0].Params, "params")
+	assert.Equalf(t, 0.5, ranges[0].Q, "q")
+	assert.Equalf(t, 0.2, ranges[1].Q, "q")
+	assert.Equalf(t, 0.0, ranges[2].Q, "invalid q")
+	assert.Equalf(t, 1.0, ranges[3].Q, "default q")
+
+	assert.Equalf(t, 0.2, ranges.Quality(render.ParseMediaType(string(render.JSON))), "wildcard subtype")
+	assert.Equalf(t, 1.0, ranges.Quality(render.ParseMediaType(string(render.Text))), "charset ignored")
+	assert.Equalf(t, 0.0, ranges.Quality(render.ParseMediaType("image/png")), "q=0")
+	assert.Equalf(t, 0.5, ranges.Quality(render.ParseMediaType("text/html; level=1")), "param matched")
+	assert.Equalf(t, 0.0, ranges.Quality(render.ParseMediaType(string(render.HTML))), "param not matched")
+}
+
+func TestAcceptNegotiater(t *testing.T) {
+	n := render.AcceptNegotiater{}
+	ctypes := []string{string(render.JSON), string(render.XML), string(render.YAML)}
+
+	ctype, err := n.Negotiate("application/xml;q=0.9, application/*;q=0.5", ctypes...)
+	assert.Nilf(t, err, "negotiate err")
+	assert.Equalf(t, string(render.XML), ctype, "higher q")
+
+	ctype, err = n.Negotiate("application/*, */*", ctypes...)
+	assert.Nilf(t, err, "negotiate err")
+	assert.Equalf(t, string(render.JSON), ctype, "server preference")
+
+	ctype, err = n.Negotiate("*/*, application/json;q=0", ctypes...)
+	assert.Nilf(t, err, "negotiate err")
+	assert.Equalf(t, string(render.XML), ctype, "more specific q=0 excludes")
+
+	_, err = n.Negotiate("text/csv", ctypes...)
+	assert.NotNilf(t, err, "not acceptable")
+}
+
+func TestSortContentTypes(t *testing.T) {
+	cts := []render.ContentType{"application/z", render.XML, "application/a", render.YAML, render.JSON}
+	render.SortContentTypes(cts)
+	assert.Equalf(t, []render.ContentType{render.JSON, render.YAML, render.XML, "application/a", "application/z"}, cts, "sorted")
+}
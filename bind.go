@@ -0,0 +1,170 @@
+package render
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+
+	"github.com/ccmonky/errors"
+	"github.com/ccmonky/inithook"
+	"go.uber.org/atomic"
+)
+
+// Decoders the request body decoders registry, key is the request content type,
+// the parameters(e.g. charset) are ignored when matching, and the first one sorted by
+// `SortContentTypes` wins if several content types match, JSON, XML and forms are registered by default,
+// import `github.com/ccmonky/render/gin` for YAML and MsgPack
+var Decoders = inithook.NewMap[ContentType, Decoder]()
+
+// Decoder defines method to decode the request body into v
+type Decoder interface {
+	Decode(r *http.Request, v any) error
+}
+
+// DecoderFunc defines the function that implement Decoder
+type DecoderFunc func(r *http.Request, v any) error
+
+func (df DecoderFunc) Decode(r *http.Request, v any) error {
+	return df(r, v)
+}
+
+// Bind decodes the request body into v with the decoder selected by `Content-Type` header,
+// the returned error is a `MetaError`, so it can be rendered by `ContentType.Err` directly:
+//
+// - `UnsupportedMediaType`(415): no decoder found for the content type
+// - `BodyTooLarge`(413): the body exceeds the limit, see `SetBindLimit`
+// - `MalformedBody`(400): the body can not be decoded
+// - `ValidationFailed`(422): the decoded value fails the validator set by `SetValidator`
+func Bind(r *http.Request, v any) error {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get(ContentTypeHeader))
+	if err != nil {
+		return errors.WithError(errors.WithMessagef(err, "invalid content type %q", r.Header.Get(ContentTypeHeader)), UnsupportedMediaType)
+	}
+	decoder, ok := getDecoder(r.Context(), mediaType)
+	if !ok {
+		return errors.WithError(errors.New("no decoder for "+mediaType), UnsupportedMediaType)
+	}
+	limit := bindLimit.Load()
+	if r.ContentLength > limit {
+		return errors.WithError(errors.New("content length exceeds the limit"), BodyTooLarge)
+	}
+	body := &limitedReader{r: r.Body, n: limit}
+	br := r.Clone(r.Context())
+	br.Body = body
+	err = decoder.Decode(br, v)
+	if br.MultipartForm != nil {
+		// NOTE: hand the parsed form over to r, so that the server removes the temporary files
+		r.MultipartForm = br.MultipartForm
+	}
+	if body.exceeded {
+		return errors.WithError(errBodyTooLarge, BodyTooLarge)
+	}
+	if err != nil {
+		if _, ok := err.(errors.MetaError); ok {
+			return err
+		}
+		return errors.WithError(err, MalformedBody)
+	}
+	return validate(v)
+}
+
+// SetBindLimit sets the max body size of `Bind`, default to 10MB
+func SetBindLimit(n int64) {
+	bindLimit.Store(n)
+}
+
+// GetDecoder returns the decoder of content type registered in `Decoders`, the same one selected by `Bind`
+func GetDecoder(ctx context.Context, ct ContentType) (Decoder, bool) {
+	return getDecoder(ctx, ParseMediaType(string(ct)).String())
+}
+
+func getDecoder(ctx context.Context, mediaType string) (Decoder, bool) {
+	decoders := Decoders.Map(ctx)
+	var cts []ContentType
+	for ct := range decoders {
+		if ParseMediaType(string(ct)).String() == mediaType {
+			cts = append(cts, ct)
+		}
+	}
+	if len(cts) == 0 {
+		return nil, false
+	}
+	SortContentTypes(cts)
+	return decoders[cts[0]], true
+}
+
+// limitedReader reads at most n bytes, and records if the body exceeds
+type limitedReader struct {
+	r        io.ReadCloser
+	n        int64
+	exceeded bool
+}
+
+func (lr *limitedReader) Read(p []byte) (int, error) {
+	if lr.exceeded {
+		return 0, errBodyTooLarge
+	}
+	if int64(len(p)) > lr.n+1 {
+		p = p[:lr.n+1]
+	}
+	n, err := lr.r.Read(p)
+	if int64(n) > lr.n {
+		lr.exceeded = true
+		return int(lr.n), errBodyTooLarge
+	}
+	lr.n -= int64(n)
+	return n, err
+}
+
+func (lr *limitedReader) Close() error {
+	return lr.r.Close()
+}
+
+func decodeJSON(r *http.Request, v any) error {
+	return json.NewDecoder(r.Body).Decode(v)
+}
+
+func decodeXML(r *http.Request, v any) error {
+	return xml.NewDecoder(r.Body).Decode(v)
+}
+
+func decodeForm(r *http.Request, v any) error {
+	if err := r.ParseForm(); err != nil {
+		return err
+	}
+	return mapForm(r.PostForm, nil, v)
+}
+
+func decodeMultipartForm(r *http.Request, v any) error {
+	if err := r.ParseMultipartForm(multipartMemory); err != nil {
+		return err
+	}
+	return mapForm(r.MultipartForm.Value, r.MultipartForm.File, v)
+}
+
+func init() {
+	ctx := context.Background()
+	err := Decoders.Register(ctx, JSON, DecoderFunc(decodeJSON))
+	err = errors.WithError(err, Decoders.Register(ctx, XML, DecoderFunc(decodeXML)))
+	err = errors.WithError(err, Decoders.Register(ctx, Form, DecoderFunc(decodeForm)))
+	err = errors.WithError(err, Decoders.Register(ctx, MultipartForm, DecoderFunc(decodeMultipartForm)))
+	if err != nil {
+		log.Panicln(errors.GetAllErrors(err))
+	}
+}
+
+const multipartMemory = 32 << 20
+
+var errBodyTooLarge = errors.New("body exceeds the limit")
+
+var (
+	bindLimit = atomic.NewInt64(10 << 20)
+)
+
+var (
+	_ Decoder = (*DecoderFunc)(nil)
+)
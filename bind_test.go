@@ -0,0 +1,117 @@
+package render_test
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ccmonky/errors"
+	"github.com/ccmonky/render"
+	"github.com/stretchr/testify/assert"
+)
+
+type bindUser struct {
+	Name    string                `json:"name" xml:"name" form:"name"`
+	Age     int                   `json:"age" xml:"age" form:"age"`
+	Tags    []string              `json:"tags" xml:"tags" form:"tag"`
+	Timeout time.Duration         `json:"-" xml:"-" form:"timeout"`
+	Avatar  *multipart.FileHeader `json:"-" xml:"-" form:"avatar"`
+}
+
+func TestBind(t *testing.T) {
+	r := httptest.NewRequest("POST", "/", strings.NewReader(`{"name":"a","age":1,"tags":["x"]}`))
+	r.Header.Set(render.ContentTypeHeader, "application/json")
+	var u bindUser
+	err := render.Bind(r, &u)
+	assert.Nilf(t, err, "json err")
+	assert.Equalf(t, bindUser{Name: "a", Age: 1, Tags: []string{"x"}}, u, "json")
+
+	r = httptest.NewRequest("POST", "/", strings.NewReader(`<user><name>b</name><age>2</age></user>`))
+	r.Header.Set(render.ContentTypeHeader, "application/xml; charset=utf-8")
+	u = bindUser{}
+	err = render.Bind(r, &u)
+	assert.Nilf(t, err, "xml err")
+	assert.Equalf(t, "b", u.Name, "xml")
+
+	r = httptest.NewRequest("POST", "/", strings.NewReader(`name=c&age=3&tag=x&tag=y&timeout=2s`))
+	r.Header.Set(render.ContentTypeHeader, string(render.Form))
+	u = bindUser{}
+	err = render.Bind(r, &u)
+	assert.Nilf(t, err, "form err")
+	assert.Equalf(t, bindUser{Name: "c", Age: 3, Tags: []string{"x", "y"}, Timeout: 2 * time.Second}, u, "form")
+
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	mw.WriteField("name", "d")
+	fw, _ := mw.CreateFormFile("avatar", "a.png")
+	fw.Write([]byte("png"))
+	mw.Close()
+	r = httptest.NewRequest("POST", "/", &buf)
+	r.Header.Set(render.ContentTypeHeader, mw.FormDataContentType())
+	u = bindUser{}
+	err = render.Bind(r, &u)
+	assert.Nilf(t, err, "multipart err")
+	assert.Equalf(t, "d", u.Name, "multipart")
+	assert.Equalf(t, "a.png", u.Avatar.Filename, "multipart file")
+	assert.Equalf(t, u.Avatar, r.MultipartForm.File["avatar"][0], "multipart form handed over")
+}
+
+func TestBindDecoderPrecedence(t *testing.T) {
+	ctx := context.Background()
+	for _, ct := range []render.ContentType{"application/x-bind; charset=utf-8", "application/x-bind", "application/x-bind; v=2"} {
+		ct := ct
+		err := render.Decoders.Register(ctx, ct, render.DecoderFunc(func(r *http.Request, v any) error {
+			v.(*bindUser).Name = string(ct)
+			return nil
+		}))
+		assert.Nilf(t, err, "register %s", ct)
+	}
+	for i := 0; i < 10; i++ {
+		r := httptest.NewRequest("POST", "/", strings.NewReader(""))
+		r.Header.Set(render.ContentTypeHeader, "application/x-bind; charset=utf-8")
+		var u bindUser
+		assert.Nilf(t, render.Bind(r, &u), "bind")
+		assert.Equalf(t, "application/x-bind", u.Name, "deterministic decoder")
+	}
+	decoder, ok := render.GetDecoder(ctx, "application/x-bind; v=2")
+	assert.Truef(t, ok, "get decoder")
+	var u bindUser
+	decoder.Decode(nil, &u)
+	assert.Equalf(t, "application/x-bind", u.Name, "same decoder as bind")
+}
+
+func TestBindErrors(t *testing.T) {
+	var u bindUser
+	r := httptest.NewRequest("POST", "/", strings.NewReader(`{}`))
+	r.Header.Set(render.ContentTypeHeader, "text/csv")
+	err := render.Bind(r, &u)
+	assert.Equalf(t, 415, errors.StatusAttr.Get(err), "unsupported media type")
+
+	r = httptest.NewRequest("POST", "/", strings.NewReader(`{"name":`))
+	r.Header.Set(render.ContentTypeHeader, "application/json")
+	err = render.Bind(r, &u)
+	assert.Equalf(t, 400, errors.StatusAttr.Get(err), "malformed")
+
+	r = httptest.NewRequest("POST", "/", strings.NewReader(`age=x`))
+	r.Header.Set(render.ContentTypeHeader, string(render.Form))
+	err = render.Bind(r, &u)
+	assert.Equalf(t, 400, errors.StatusAttr.Get(err), "malformed form")
+
+	render.SetBindLimit(8)
+	defer render.SetBindLimit(10 << 20)
+	r = httptest.NewRequest("POST", "/", strings.NewReader(`{"name":"too large"}`))
+	r.Header.Set(render.ContentTypeHeader, "application/json")
+	r.ContentLength = -1
+	err = render.Bind(r, &u)
+	assert.Equalf(t, 413, errors.StatusAttr.Get(err), "too large")
+
+	w := httptest.NewRecorder()
+	render.JSON.Err(w, r, err)
+	assert.Equalf(t, 413, w.Code, "rendered status")
+	assert.Equalf(t, "body_too_large", w.Header().Get("X-Code"), "x-code")
+}
@@ -0,0 +1,113 @@
+package render
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// Streamer can be implemented by `Render` which writes the body progressively,
+// `ContentType.Render` never buffers the body if `Streaming` returns true
+type Streamer interface {
+	Streaming() bool
+}
+
+// Unbuffered used to disable the encode-then-commit mode of `ContentType.Render`
+func Unbuffered() Option {
+	return func(o any) {
+		if options, ok := o.(*renderOptions); ok {
+			options.Unbuffered = true
+		}
+	}
+}
+
+// bufferedWriter collects the status, headers and body written by render, nothing reaches
+// the underlying writer until `commit` is called
+type bufferedWriter struct {
+	header  http.Header
+	written http.Header // NOTE: snapshot of header when status written, later changes are ignored like `http.ResponseWriter`
+	status  int
+	buf     *bytes.Buffer
+}
+
+func newBufferedWriter(w http.ResponseWriter) *bufferedWriter {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	header := w.Header().Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	return &bufferedWriter{
+		header: header,
+		buf:    buf,
+	}
+}
+
+func (bw *bufferedWriter) Header() http.Header {
+	return bw.header
+}
+
+// WriteHeader records the first valid status only, like `http.ResponseWriter` does
+func (bw *bufferedWriter) WriteHeader(status int) {
+	if bw.status == 0 && status >= 100 {
+		bw.status = status
+		bw.written = bw.header.Clone()
+	}
+}
+
+func (bw *bufferedWriter) Write(data []byte) (int, error) {
+	if bw.status == 0 {
+		bw.WriteHeader(http.StatusOK)
+	}
+	return bw.buf.Write(data)
+}
+
+// commit writes the buffered status, headers and body into w, `Content-Length` is set if absent
+func (bw *bufferedWriter) commit(w http.ResponseWriter) error {
+	written := bw.written
+	if written == nil {
+		written = bw.header
+	}
+	header := w.Header()
+	for k := range header {
+		if _, ok := written[k]; !ok {
+			delete(header, k)
+		}
+	}
+	for k, vs := range written {
+		header[k] = vs
+	}
+	status := bw.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	if bodyAllowed(status) && header.Get("Content-Length") == "" && header.Get("Transfer-Encoding") == "" {
+		header.Set("Content-Length", strconv.Itoa(bw.buf.Len()))
+	}
+	w.WriteHeader(status)
+	if bw.buf.Len() == 0 {
+		return nil
+	}
+	_, err := w.Write(bw.buf.Bytes())
+	return err
+}
+
+func (bw *bufferedWriter) release() {
+	if bw.buf.Cap() <= maxPooledBufferSize {
+		bufferPool.Put(bw.buf)
+	}
+	bw.buf = nil
+}
+
+func bodyAllowed(status int) bool {
+	return status >= 200 && status != http.StatusNoContent && status != http.StatusNotModified
+}
+
+const maxPooledBufferSize = 64 << 10
+
+var bufferPool = sync.Pool{
+	New: func() any {
+		return new(bytes.Buffer)
+	},
+}
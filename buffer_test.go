@@ -0,0 +1,37 @@
+package render_test
+
+import (
+	"math"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/ccmonky/render"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuffered(t *testing.T) {
+	w := httptest.NewRecorder()
+	err := render.JSON.Render(w, render.NewResponse(map[string]any{"one": 1}, render.T("no_timestamp")))
+	assert.Nilf(t, err, "render err")
+	assert.Equalf(t, 200, w.Code, "status")
+	assert.Equalf(t, strconv.Itoa(w.Body.Len()), w.Header().Get("Content-Length"), "content-length")
+
+	w = httptest.NewRecorder()
+	w.Header().Set("X-Before", "1")
+	err = render.JSON.Render(w, render.NewResponse(map[string]any{"nan": math.NaN()}, render.T("no_timestamp")))
+	assert.NotNilf(t, err, "render err")
+	assert.Equalf(t, 500, w.Code, "status")
+	assert.Equalf(t, "1", w.Header().Get("X-Before"), "header before render kept")
+	assert.Equalf(t, "unknown(2)", w.Header().Get("X-Code"), "x-code")
+	assert.Equalf(t, "", w.Header().Get("X-Render-Template"), "template replaced")
+	assert.Equalf(t, string(render.JSON), w.Header().Get("Content-Type"), "content-type")
+	assert.Containsf(t, w.Body.String(), `"code":"unknown(2)"`, "body")
+	assert.NotContainsf(t, w.Body.String(), `"nan"`, "body")
+
+	w = httptest.NewRecorder()
+	err = render.JSON.Render(w, render.NewResponse(map[string]any{"nan": math.NaN()}), render.Unbuffered())
+	assert.NotNilf(t, err, "render err")
+	assert.Equalf(t, 200, w.Code, "unbuffered status")
+	assert.Equalf(t, "", w.Header().Get("Content-Length"), "unbuffered content-length")
+}
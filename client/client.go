@@ -0,0 +1,230 @@
+// Package client decodes the responses rendered by render on the caller side, the error meta(`code`, `message`, `detail`)
+// is parsed from the envelope, or the `X-Code`, `X-Message`, `X-Detail` headers(decoded, see `render.DecodeHeaderValue`) for non-JSON bodies, and returned as
+// `*RemoteError`, which compares equal to the `MetaError` with the same code, so `errors.Is` works across the wire:
+//
+//	resp, err := http.Get(url)
+//	...
+//	var user User
+//	err = client.Decode(resp, &user)
+//	if errors.Is(err, ErrUserNotFound) {
+//		...
+//	}
+//
+// the known errors are looked up from `render.MetaErrors`, the errors of unknown code are still `ErrRemote`.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/ccmonky/errors"
+	"github.com/ccmonky/render"
+)
+
+// ErrRemote every `RemoteError` is it, used to tell the remote errors, especially those of unknown code
+var ErrRemote = errors.New("remote error")
+
+// RemoteError the error decoded from the response, it implements `MetaError` with the remote code and message,
+// and unwraps to the local `MetaError` of the same code if known
+type RemoteError struct {
+	status  int
+	app     string
+	code    string
+	message string
+	detail  string
+	meta    errors.MetaError
+}
+
+// Status returns the http status of response
+func (e *RemoteError) Status() int {
+	return e.status
+}
+
+// Source returns the remote app name
+func (e *RemoteError) Source() string {
+	return e.app
+}
+
+// Code returns the remote error code
+func (e *RemoteError) Code() string {
+	return e.code
+}
+
+// Message returns the remote error message
+func (e *RemoteError) Message() string {
+	return e.message
+}
+
+// Detail returns the remote error detail
+func (e *RemoteError) Detail() string {
+	return e.detail
+}
+
+// Error implement `error`
+func (e *RemoteError) Error() string {
+	return fmt.Sprintf("remote error: status=%d;code=%s;message=%s;detail=%s", e.status, e.code, e.message, e.detail)
+}
+
+// Is tells if the target is `ErrRemote`, or a `MetaError` with the same code
+func (e *RemoteError) Is(target error) bool {
+	if target == ErrRemote {
+		return true
+	}
+	if me, ok := target.(errors.MetaError); ok {
+		return e.code != "" && me.Code() == e.code
+	}
+	return false
+}
+
+// Unwrap returns the local `MetaError` of the same code, nil if unknown
+func (e *RemoteError) Unwrap() error {
+	if e.meta == nil {
+		return nil
+	}
+	return e.meta
+}
+
+// Decode reads and closes the response body, if the response carries an error, returns it as `*RemoteError`,
+// otherwise decodes the `data` member of envelope(or the whole body if not an envelope) into data:
+// JSON is unmarshaled, `*[]byte` and `*string` receive the raw body, nil data discards it.
+func Decode(resp *http.Response, data any) error {
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return errors.WithMessagef(err, "read response body failed")
+	}
+	isJSON := isJSON(resp.Header.Get(render.ContentTypeHeader))
+	if isJSON {
+		var env envelope
+		if json.Unmarshal(body, &env) == nil && env.Code != "" {
+			if rerr := newRemoteError(resp.StatusCode, env.App, env.Code, env.message(), env.Detail); rerr != nil {
+				return rerr
+			}
+			if len(env.Data) == 0 {
+				return nil
+			}
+			return decodeData(env.Data, true, data)
+		}
+	}
+	code := metaHeader(resp, render.MetaCode)
+	if rerr := newRemoteError(resp.StatusCode, metaHeader(resp, render.MetaApp), code, metaHeader(resp, render.MetaMessage), metaHeader(resp, render.MetaDetail)); rerr != nil {
+		return rerr
+	}
+	return decodeData(body, isJSON, data)
+}
+
+// metaHeader returns the decoded meta header named by the default `render.Renderer`
+func metaHeader(resp *http.Response, key string) string {
+	return render.DecodeHeaderValue(resp.Header.Get(render.Default().HeaderName(key)))
+}
+
+// Transport wraps the base `http.RoundTripper`(`http.DefaultTransport` if nil), the responses carrying errors
+// are consumed and returned as the `*RemoteError`(wrapped by `*url.Error` of `http.Client`), e.g.
+//
+//	c := &http.Client{Transport: client.Transport(nil)}
+//	resp, err := c.Get(url) // errors.Is(err, errors.NotFound)
+func Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{base: base}
+}
+
+type transport struct {
+	base http.RoundTripper
+}
+
+// RoundTrip implement `http.RoundTripper`
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+	code := metaHeader(resp, render.MetaCode)
+	if resp.StatusCode < 400 && (code == "" || code == errors.OK.Code()) {
+		return resp, nil
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, errors.WithMessagef(err, "read response body failed")
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err := Decode(resp, nil); err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
+
+// envelope the error meta members of `Response` body and problem details
+type envelope struct {
+	App     string          `json:"app"`
+	Code    string          `json:"code"`
+	Message string          `json:"message"`
+	Title   string          `json:"title"`
+	Detail  string          `json:"detail"`
+	Data    json.RawMessage `json:"data"`
+}
+
+func (env envelope) message() string {
+	if env.Message != "" {
+		return env.Message
+	}
+	return env.Title
+}
+
+// newRemoteError returns nil if the response is success
+func newRemoteError(status int, app, code, message, detail string) *RemoteError {
+	if code == errors.OK.Code() || (code == "" && status < 400) {
+		return nil
+	}
+	rerr := &RemoteError{
+		status:  status,
+		app:     app,
+		code:    code,
+		message: message,
+		detail:  detail,
+	}
+	if code != "" {
+		if meta, err := render.MetaErrors.Get(context.Background(), code); err == nil {
+			rerr.meta = meta
+		}
+	}
+	if rerr.message == "" {
+		rerr.message = http.StatusText(status)
+	}
+	return rerr
+}
+
+func decodeData(body []byte, isJSON bool, data any) error {
+	switch data := data.(type) {
+	case nil:
+		return nil
+	case *[]byte:
+		*data = append((*data)[:0], body...)
+		return nil
+	case *string:
+		*data = string(body)
+		return nil
+	}
+	if !isJSON {
+		return fmt.Errorf("can not decode non-JSON body into %T", data)
+	}
+	return json.Unmarshal(body, data)
+}
+
+func isJSON(contentType string) bool {
+	mt := render.ParseMediaType(contentType)
+	return mt.Type == "application" && (mt.Subtype == "json" || strings.HasSuffix(mt.Subtype, "+json"))
+}
+
+var (
+	_ errors.MetaError  = (*RemoteError)(nil)
+	_ http.RoundTripper = (*transport)(nil)
+)
@@ -0,0 +1,104 @@
+package client_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ccmonky/errors"
+	"github.com/ccmonky/render"
+	"github.com/ccmonky/render/client"
+	"github.com/stretchr/testify/assert"
+)
+
+type user struct {
+	Name string `json:"name"`
+}
+
+var errRemoteOnly = errors.NewMetaError("remote", "remote_only", "remote only", errors.WithStatus(http.StatusConflict))
+
+func newServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+			render.JSON.OK(w, r, user{Name: "a"})
+		case "/not_found":
+			render.JSON.Err(w, r, errors.NotFound)
+		case "/problem":
+			render.ProblemJSON.Err(w, r, render.NotAcceptable)
+		case "/unknown":
+			render.JSON.Err(w, r, errRemoteOnly)
+		case "/text":
+			w.Header().Set("Content-Type", "text/plain")
+			w.Header().Set("X-Code", errors.InvalidArgument.Code())
+			w.Header().Set("X-Message", "bad name")
+			w.WriteHeader(http.StatusBadRequest)
+			io.WriteString(w, "bad name")
+		case "/plain":
+			http.Error(w, "boom", http.StatusBadGateway)
+		}
+	}))
+}
+
+func TestDecode(t *testing.T) {
+	ts := newServer()
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/ok")
+	assert.Nilf(t, err, "get ok")
+	var u user
+	err = client.Decode(resp, &u)
+	assert.Nilf(t, err, "decode ok")
+	assert.Equalf(t, "a", u.Name, "data")
+
+	resp, _ = http.Get(ts.URL + "/not_found")
+	err = client.Decode(resp, &u)
+	assert.Truef(t, errors.Is(err, errors.NotFound), "not found")
+	assert.Truef(t, errors.Is(err, client.ErrRemote), "remote")
+	assert.Falsef(t, errors.Is(err, errors.InvalidArgument), "not invalid argument")
+	var rerr *client.RemoteError
+	assert.Truef(t, errors.As(err, &rerr), "as remote error")
+	assert.Equalf(t, 404, rerr.Status(), "status")
+	assert.Equalf(t, "not found", rerr.Message(), "message")
+
+	resp, _ = http.Get(ts.URL + "/problem")
+	err = client.Decode(resp, nil)
+	assert.Truef(t, errors.Is(err, render.NotAcceptable), "problem")
+
+	resp, _ = http.Get(ts.URL + "/unknown")
+	err = client.Decode(resp, nil)
+	assert.Truef(t, errors.Is(err, client.ErrRemote), "unknown remote")
+	assert.Truef(t, errors.As(err, &rerr), "unknown as remote error")
+	assert.Equalf(t, 409, rerr.Status(), "unknown status")
+	assert.Equalf(t, "remote_only", rerr.Code(), "unknown code")
+
+	resp, _ = http.Get(ts.URL + "/text")
+	err = client.Decode(resp, nil)
+	assert.Truef(t, errors.Is(err, errors.InvalidArgument), "text headers")
+	assert.Truef(t, errors.As(err, &rerr), "text as remote error")
+	assert.Equalf(t, "bad name", rerr.Message(), "text message")
+
+	resp, _ = http.Get(ts.URL + "/plain")
+	err = client.Decode(resp, nil)
+	assert.Truef(t, errors.Is(err, client.ErrRemote), "plain remote")
+	assert.Truef(t, errors.As(err, &rerr), "plain as remote error")
+	assert.Equalf(t, 502, rerr.Status(), "plain status")
+	assert.Equalf(t, "Bad Gateway", rerr.Message(), "plain message")
+}
+
+func TestTransport(t *testing.T) {
+	ts := newServer()
+	defer ts.Close()
+	c := &http.Client{Transport: client.Transport(nil)}
+
+	resp, err := c.Get(ts.URL + "/ok")
+	assert.Nilf(t, err, "get ok")
+	var u user
+	err = client.Decode(resp, &u)
+	assert.Nilf(t, err, "decode ok")
+	assert.Equalf(t, "a", u.Name, "data")
+
+	_, err = c.Get(ts.URL + "/not_found")
+	assert.Truef(t, errors.Is(err, errors.NotFound), "not found")
+}
@@ -0,0 +1,362 @@
+package render
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ccmonky/errors"
+	"github.com/ccmonky/inithook"
+)
+
+const (
+	// AcceptEncodingHeader `Accept-Encoding` header name
+	AcceptEncodingHeader = "Accept-Encoding"
+
+	// ContentEncodingHeader `Content-Encoding` header name
+	ContentEncodingHeader = "Content-Encoding"
+)
+
+// Encoders the content encoders registry, key is the content coding, e.g. gzip, deflate,
+// zstd or br can be registered with third party libraries, e.g.
+//
+//	render.Encoders.Register(ctx, "zstd", render.NewEncoder(func(w io.Writer) render.EncoderWriter {
+//		zw, _ := zstd.NewWriter(w)
+//		return zw
+//	}))
+var Encoders = inithook.NewMap[string, *Encoder]()
+
+// EncoderWriter defines the compressing writer, `Reset` is used to reuse it by pool
+type EncoderWriter interface {
+	io.WriteCloser
+	Reset(w io.Writer)
+}
+
+// Encoder pools the `EncoderWriter`s of content coding
+type Encoder struct {
+	pool sync.Pool
+}
+
+// NewEncoder creates a new `Encoder` with the `EncoderWriter` constructor
+func NewEncoder(fn func(w io.Writer) EncoderWriter) *Encoder {
+	return &Encoder{
+		pool: sync.Pool{
+			New: func() any {
+				return fn(io.Discard)
+			},
+		},
+	}
+}
+
+// Get returns a pooled `EncoderWriter` writes into w
+func (e *Encoder) Get(w io.Writer) EncoderWriter {
+	ew := e.pool.Get().(EncoderWriter)
+	ew.Reset(w)
+	return ew
+}
+
+// Put returns ew to pool, ew should be closed before
+func (e *Encoder) Put(ew EncoderWriter) {
+	ew.Reset(io.Discard)
+	e.pool.Put(ew)
+}
+
+// CompressMinSize used to specify the min body size to compress, default to 1024
+func CompressMinSize(size int) Option {
+	return func(o any) {
+		if options, ok := o.(*compressOptions); ok {
+			options.MinSize = size
+		}
+	}
+}
+
+// CompressEncodings used to specify the content codings in server preference order, default to br, zstd, gzip, deflate,
+// the codings not registered in `Encoders` are ignored
+func CompressEncodings(encodings ...string) Option {
+	return func(o any) {
+		if options, ok := o.(*compressOptions); ok {
+			options.Encodings = encodings
+		}
+	}
+}
+
+// CompressSkipTypes used to specify the media type prefixes not compressed, default to already compressed types,
+// e.g. `image/`, `video/`, `audio/`, `application/zip` and `Binary`
+func CompressSkipTypes(prefixes ...string) Option {
+	return func(o any) {
+		if options, ok := o.(*compressOptions); ok {
+			options.SkipTypes = prefixes
+		}
+	}
+}
+
+type compressOptions struct {
+	MinSize   int
+	Encodings []string
+	SkipTypes []string
+	Request   *http.Request
+}
+
+func (options *compressOptions) setRequest(r *http.Request) {
+	options.Request = r
+}
+
+// Compress wraps the render(e.g. `ContentType`) with compression, the content coding is negotiated from the `Accept-Encoding`
+// header of the request specified by `ForRequest`(`ContentType.OK` and `ContentType.Err` of the wrapped content type don't know it),
+// so use it like:
+//
+//	var gzipJSON = render.Compress(render.JSON)
+//
+//	gzipJSON.Render(w, render.NewResponse(data), render.ForRequest(r))
+//
+// the body smaller than `CompressMinSize` and the skipped types(see `CompressSkipTypes`) are not compressed.
+func Compress(render Render, opts ...Option) Render {
+	options := compressOptions{
+		MinSize:   1024,
+		Encodings: []string{"br", "zstd", "gzip", "deflate"},
+		SkipTypes: []string{"image/", "video/", "audio/", "application/zip", "application/gzip", "application/x-gzip", string(Binary)},
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return &compressRender{
+		render:  render,
+		options: options,
+	}
+}
+
+type compressRender struct {
+	render  Render
+	options compressOptions
+}
+
+// Streaming implement `Streamer`, same as the wrapped render
+func (cr *compressRender) Streaming() bool {
+	s, ok := cr.render.(Streamer)
+	return ok && s.Streaming()
+}
+
+// Render implement `Render`
+func (cr *compressRender) Render(w http.ResponseWriter, data any, opts ...Option) error {
+	options := cr.options
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.Request == nil {
+		return cr.render.Render(w, data, opts...)
+	}
+	coding, encoder := negotiateEncoding(options.Request.Header.Get(AcceptEncodingHeader), options.Encodings)
+	cw := &compressWriter{
+		ResponseWriter: w,
+		options:        &options,
+		coding:         coding,
+		encoder:        encoder,
+	}
+	err := cr.render.Render(cw, data, opts...)
+	if cerr := cw.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// negotiateEncoding returns the acceptable content coding with highest q-value, ties are broken by server preference
+func negotiateEncoding(header string, encodings []string) (string, *Encoder) {
+	if header == "" {
+		return "", nil
+	}
+	qs := make(map[string]float64)
+	for _, item := range strings.Split(header, ",") {
+		parts := strings.Split(item, ";")
+		coding := strings.ToLower(strings.TrimSpace(parts[0]))
+		if coding == "" {
+			continue
+		}
+		q := 1.0
+		for _, p := range parts[1:] {
+			k, v, _ := strings.Cut(p, "=")
+			if strings.EqualFold(strings.TrimSpace(k), "q") {
+				var err error
+				if q, err = strconv.ParseFloat(strings.TrimSpace(v), 64); err != nil {
+					q = 0
+				}
+			}
+		}
+		qs[coding] = q
+	}
+	var (
+		best    string
+		bestQ   float64
+		encoder *Encoder
+	)
+	for _, coding := range encodings {
+		q, ok := qs[coding]
+		if !ok {
+			q = qs["*"]
+		}
+		if q <= bestQ {
+			continue
+		}
+		if e, err := Encoders.Get(context.Background(), coding); err == nil {
+			best, bestQ, encoder = coding, q, e
+		}
+	}
+	return best, encoder
+}
+
+// compressWriter decides whether to compress when the status is written, if the body size is unknown,
+// the body is held until `CompressMinSize` reached
+type compressWriter struct {
+	http.ResponseWriter
+	options *compressOptions
+	coding  string
+	encoder *Encoder
+
+	status  int
+	decided bool
+	pending []byte
+	writer  EncoderWriter
+}
+
+func (cw *compressWriter) WriteHeader(status int) {
+	if cw.status != 0 || status < 100 {
+		return
+	}
+	cw.status = status
+	header := cw.Header()
+	if !bodyAllowed(status) || header.Get(ContentEncodingHeader) != "" || !cw.compressible(header.Get(ContentTypeHeader)) {
+		cw.decide(false)
+		return
+	}
+	addVary(header, AcceptEncodingHeader)
+	if cw.encoder == nil {
+		cw.decide(false)
+		return
+	}
+	if cl := header.Get("Content-Length"); cl != "" {
+		size, err := strconv.Atoi(cl)
+		cw.decide(err == nil && size >= cw.options.MinSize)
+	}
+}
+
+func (cw *compressWriter) Write(data []byte) (int, error) {
+	if cw.status == 0 {
+		cw.WriteHeader(http.StatusOK)
+	}
+	if cw.decided {
+		if cw.writer != nil {
+			return cw.writer.Write(data)
+		}
+		return cw.ResponseWriter.Write(data)
+	}
+	cw.pending = append(cw.pending, data...)
+	if len(cw.pending) >= cw.options.MinSize {
+		if err := cw.decide(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(data), nil
+}
+
+// Flush implement `http.Flusher`, the pending body is compressed if not decided
+func (cw *compressWriter) Flush() {
+	if cw.status != 0 && !cw.decided {
+		cw.decide(true)
+	}
+	if f, ok := cw.writer.(interface{ Flush() error }); ok {
+		f.Flush()
+	}
+	if f, ok := cw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Close decides for the small body and closes the encoder writer
+func (cw *compressWriter) Close() error {
+	if cw.status != 0 && !cw.decided {
+		if err := cw.decide(false); err != nil {
+			return err
+		}
+	}
+	if cw.writer == nil {
+		return nil
+	}
+	err := cw.writer.Close()
+	cw.encoder.Put(cw.writer)
+	cw.writer = nil
+	return err
+}
+
+// decide writes the header and the pending body
+func (cw *compressWriter) decide(compress bool) error {
+	cw.decided = true
+	if compress {
+		header := cw.Header()
+		header.Set(ContentEncodingHeader, cw.coding)
+		header.Del("Content-Length")
+		if etag := header.Get(ETagHeader); strings.HasPrefix(etag, `"`) {
+			// NOTE: the compressed representation is not byte-identical to the one the strong tag was computed from
+			header.Set(ETagHeader, "W/"+etag)
+		}
+		cw.writer = cw.encoder.Get(cw.ResponseWriter)
+	}
+	cw.ResponseWriter.WriteHeader(cw.status)
+	if len(cw.pending) == 0 {
+		return nil
+	}
+	var err error
+	if cw.writer != nil {
+		_, err = cw.writer.Write(cw.pending)
+	} else {
+		_, err = cw.ResponseWriter.Write(cw.pending)
+	}
+	cw.pending = nil
+	return err
+}
+
+func (cw *compressWriter) compressible(contentType string) bool {
+	mt := ParseMediaType(contentType).String()
+	for _, prefix := range cw.options.SkipTypes {
+		if strings.HasPrefix(mt, strings.ToLower(prefix)) {
+			return false
+		}
+	}
+	return true
+}
+
+// addVary adds value into `Vary` header if not exists
+func addVary(header http.Header, value string) {
+	for _, vs := range header.Values("Vary") {
+		for _, v := range strings.Split(vs, ",") {
+			if strings.EqualFold(strings.TrimSpace(v), value) {
+				return
+			}
+		}
+	}
+	header.Add("Vary", value)
+}
+
+func init() {
+	ctx := context.Background()
+	err := Encoders.Register(ctx, "gzip", NewEncoder(func(w io.Writer) EncoderWriter {
+		return gzip.NewWriter(w)
+	}))
+	// NOTE: the http `deflate` content coding is the zlib format(RFC 1950), not the raw deflate
+	err = errors.WithError(err, Encoders.Register(ctx, "deflate", NewEncoder(func(w io.Writer) EncoderWriter {
+		zw, _ := zlib.NewWriterLevel(w, zlib.DefaultCompression)
+		return zw
+	})))
+	if err != nil {
+		log.Panicln(errors.GetAllErrors(err))
+	}
+}
+
+var (
+	_ Render   = (*compressRender)(nil)
+	_ Streamer = (*compressRender)(nil)
+)
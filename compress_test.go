@@ -0,0 +1,66 @@
+package render_test
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ccmonky/render"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompress(t *testing.T) {
+	data := map[string]string{"text": strings.Repeat("compress me ", 200)}
+	gzipJSON := render.Compress(render.JSON)
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set(render.AcceptEncodingHeader, "deflate;q=0.5, gzip")
+	w := httptest.NewRecorder()
+	err := gzipJSON.Render(w, render.NewResponse(data), render.ForRequest(r), render.ETag(`"v1"`))
+	assert.Nilf(t, err, "render err")
+	assert.Equalf(t, 200, w.Code, "status")
+	assert.Equalf(t, "gzip", w.Header().Get("Content-Encoding"), "content-encoding")
+	assert.Equalf(t, `W/"v1"`, w.Header().Get(render.ETagHeader), "weakened etag")
+	assert.Equalf(t, "Accept-Encoding", w.Header().Get("Vary"), "vary")
+	assert.Equalf(t, "", w.Header().Get("Content-Length"), "content-length")
+	assert.Equalf(t, "success(0)", w.Header().Get("X-Code"), "x-code")
+	zr, err := gzip.NewReader(w.Body)
+	assert.Nilf(t, err, "gzip reader")
+	body, err := io.ReadAll(zr)
+	assert.Nilf(t, err, "gzip read")
+	assert.Containsf(t, string(body), "compress me compress me", "body")
+
+	r.Header.Set(render.AcceptEncodingHeader, "gzip;q=0, *")
+	w = httptest.NewRecorder()
+	err = gzipJSON.Render(w, data, render.ForRequest(r))
+	assert.Nilf(t, err, "render err")
+	assert.Equalf(t, "deflate", w.Header().Get("Content-Encoding"), "content-encoding")
+	zlr, err := zlib.NewReader(w.Body)
+	assert.Nilf(t, err, "zlib reader")
+	body, err = io.ReadAll(zlr)
+	assert.Nilf(t, err, "deflate read")
+	assert.Containsf(t, string(body), "compress me compress me", "body")
+
+	w = httptest.NewRecorder()
+	err = gzipJSON.Render(w, render.NewResponse("small"), render.ForRequest(r))
+	assert.Nilf(t, err, "render err")
+	assert.Equalf(t, "", w.Header().Get("Content-Encoding"), "small body")
+	assert.Equalf(t, "Accept-Encoding", w.Header().Get("Vary"), "vary")
+	assert.Equalf(t, strconv.Itoa(w.Body.Len()), w.Header().Get("Content-Length"), "content-length")
+
+	w = httptest.NewRecorder()
+	w.Header().Set(render.ContentTypeHeader, "image/png")
+	err = gzipJSON.Render(w, data, render.ForRequest(r))
+	assert.Nilf(t, err, "render err")
+	assert.Equalf(t, "", w.Header().Get("Content-Encoding"), "skipped type")
+	assert.Equalf(t, "", w.Header().Get("Vary"), "skipped type vary")
+
+	w = httptest.NewRecorder()
+	err = gzipJSON.Render(w, data)
+	assert.Nilf(t, err, "render err")
+	assert.Equalf(t, "", w.Header().Get("Content-Encoding"), "no request")
+}
@@ -0,0 +1,155 @@
+package render
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// ETagHeader `ETag` header name
+	ETagHeader = "ETag"
+
+	// LastModifiedHeader `Last-Modified` header name
+	LastModifiedHeader = "Last-Modified"
+
+	// IfNoneMatchHeader `If-None-Match` header name
+	IfNoneMatchHeader = "If-None-Match"
+
+	// IfModifiedSinceHeader `If-Modified-Since` header name
+	IfModifiedSinceHeader = "If-Modified-Since"
+)
+
+// ETag used to specify the entity tag of `ContentType.Render`, e.g. `"v1"` or `W/"v1"`
+func ETag(etag string) Option {
+	return func(o any) {
+		if options, ok := o.(*renderOptions); ok {
+			options.ETag = etag
+		}
+	}
+}
+
+// GenerateETag used to compute the entity tag of `ContentType.Render` from the encoded body if not specified,
+// note that the body of default `Response` carries timestamp, use a template without it to make the tag stable
+func GenerateETag(weak bool) Option {
+	return func(o any) {
+		if options, ok := o.(*renderOptions); ok {
+			options.GenerateETag = true
+			options.WeakETag = weak
+		}
+	}
+}
+
+// LastModified used to specify the last modification time of `ContentType.Render`
+func LastModified(t time.Time) Option {
+	return func(o any) {
+		if options, ok := o.(*renderOptions); ok {
+			options.LastModified = t
+		}
+	}
+}
+
+// validators sets the validator headers specified by options
+func (options *renderOptions) validators(header http.Header) {
+	if options.ETag != "" {
+		header.Set(ETagHeader, options.ETag)
+	}
+	if !options.LastModified.IsZero() {
+		header.Set(LastModifiedHeader, options.LastModified.UTC().Format(http.TimeFormat))
+	}
+}
+
+// conditional generates the entity tag if needed, and turns the buffered response into 304 if not modified
+func (options *renderOptions) conditional(bw *bufferedWriter) {
+	header := bw.written
+	if header == nil {
+		header = bw.header
+	}
+	status := bw.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	if options.GenerateETag && status == http.StatusOK && header.Get(ETagHeader) == "" {
+		header.Set(ETagHeader, computeETag(bw.buf.Bytes(), options.WeakETag))
+	}
+	if options.notModified(status, header) {
+		header.Del(ContentTypeHeader)
+		bw.status = http.StatusNotModified
+		bw.buf.Reset()
+	}
+}
+
+// unbufferedNotModified sets the validators of options and data(if it's a `ResponseInterface`) to header,
+// and evaluates the conditional request with the status of data before anything is written
+func (options *renderOptions) unbufferedNotModified(header http.Header, data any) bool {
+	options.validators(header)
+	status := http.StatusOK
+	if rp, ok := data.(ResponseInterface); ok {
+		status = rp.Status()
+		for k, vs := range rp.Header() {
+			if isValidator(k) {
+				header[k] = vs
+			}
+		}
+	}
+	return options.notModified(status, header)
+}
+
+// notModified evaluates `If-None-Match` and `If-Modified-Since` of GET or HEAD request against header(RFC 9110 section 13.2.2)
+func (options *renderOptions) notModified(status int, header http.Header) bool {
+	r := options.Request
+	if r == nil || status != http.StatusOK || (r.Method != http.MethodGet && r.Method != http.MethodHead) {
+		return false
+	}
+	if inm := r.Header.Get(IfNoneMatchHeader); inm != "" {
+		etag := header.Get(ETagHeader)
+		if etag == "" {
+			return false
+		}
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || weakMatch(candidate, etag) {
+				return true
+			}
+		}
+		return false
+	}
+	ims, err := http.ParseTime(r.Header.Get(IfModifiedSinceHeader))
+	if err != nil {
+		return false
+	}
+	lm, err := http.ParseTime(header.Get(LastModifiedHeader))
+	if err != nil {
+		return false
+	}
+	return !lm.Truncate(time.Second).After(ims)
+}
+
+// writeNotModified writes 304 without body and representation headers
+func writeNotModified(w http.ResponseWriter) {
+	header := w.Header()
+	header.Del(ContentTypeHeader)
+	header.Del("Content-Length")
+	w.WriteHeader(http.StatusNotModified)
+}
+
+// isValidator tells if the canonical header key is a validator header
+func isValidator(key string) bool {
+	return key == http.CanonicalHeaderKey(ETagHeader) || key == http.CanonicalHeaderKey(LastModifiedHeader)
+}
+
+func computeETag(body []byte, weak bool) string {
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	if weak {
+		return "W/" + etag
+	}
+	return etag
+}
+
+// weakMatch compares entity tags with weak comparison
+func weakMatch(a, b string) bool {
+	return strings.TrimPrefix(a, "W/") == strings.TrimPrefix(b, "W/")
+}
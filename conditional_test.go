@@ -0,0 +1,90 @@
+package render_test
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ccmonky/errors"
+	"github.com/ccmonky/render"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConditional(t *testing.T) {
+	data := map[string]any{"one": 1}
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set(render.TemplateHeader, "no_timestamp")
+	r.Header.Set(render.RequestIDHeader, "req-1")
+
+	w := httptest.NewRecorder()
+	err := render.JSON.OK(w, r, data, render.GenerateETag(false))
+	assert.Nilf(t, err, "render err")
+	assert.Equalf(t, 200, w.Code, "status")
+	etag := w.Header().Get(render.ETagHeader)
+	assert.Regexpf(t, `^"[0-9a-f]{32}"$`, etag, "strong etag")
+
+	r.Header.Set(render.IfNoneMatchHeader, `"other", W/`+etag)
+	w = httptest.NewRecorder()
+	err = render.JSON.OK(w, r, data, render.GenerateETag(true))
+	assert.Nilf(t, err, "render err")
+	assert.Equalf(t, 304, w.Code, "not modified")
+	assert.Equalf(t, "W/"+etag, w.Header().Get(render.ETagHeader), "weak etag")
+	assert.Equalf(t, "", w.Header().Get(render.ContentTypeHeader), "no content type")
+	assert.Equalf(t, 0, w.Body.Len(), "no body")
+
+	w = httptest.NewRecorder()
+	err = render.JSON.OK(w, r, map[string]any{"one": 2}, render.GenerateETag(false))
+	assert.Nilf(t, err, "render err")
+	assert.Equalf(t, 200, w.Code, "modified")
+
+	lm := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	r.Header.Del(render.IfNoneMatchHeader)
+	r.Header.Set(render.IfModifiedSinceHeader, lm.Format("Mon, 02 Jan 2006 15:04:05 GMT"))
+	w = httptest.NewRecorder()
+	err = render.JSON.OK(w, r, render.NewResponse(data, render.WithLastModified(lm.Add(time.Millisecond))))
+	assert.Nilf(t, err, "render err")
+	assert.Equalf(t, 304, w.Code, "not modified since")
+
+	w = httptest.NewRecorder()
+	err = render.JSON.OK(w, r, data, render.LastModified(lm.Add(time.Hour)), render.Unbuffered())
+	assert.Nilf(t, err, "render err")
+	assert.Equalf(t, 200, w.Code, "modified since")
+
+	w = httptest.NewRecorder()
+	err = render.JSON.OK(w, r, data, render.LastModified(lm), render.Unbuffered())
+	assert.Nilf(t, err, "render err")
+	assert.Equalf(t, 304, w.Code, "unbuffered not modified")
+
+	w = httptest.NewRecorder()
+	err = render.JSON.Render(w, render.NewResponse(nil, render.E(errors.NotFound)), render.ForRequest(r), render.LastModified(lm), render.Unbuffered())
+	assert.Nilf(t, err, "render err")
+	assert.Equalf(t, 404, w.Code, "unbuffered error not conditional")
+
+	r.Header.Del(render.IfModifiedSinceHeader)
+	r.Header.Set(render.IfNoneMatchHeader, `"v2"`)
+	w = httptest.NewRecorder()
+	err = render.JSON.Render(w, render.NewResponse(data, render.WithETag(`"v2"`)), render.ForRequest(r), render.Unbuffered())
+	assert.Nilf(t, err, "render err")
+	assert.Equalf(t, 304, w.Code, "unbuffered response etag")
+	assert.Equalf(t, `"v2"`, w.Header().Get(render.ETagHeader), "unbuffered response etag kept")
+
+	w = httptest.NewRecorder()
+	err = render.NDJSON.Render(w, []int{1}, render.ForRequest(r), render.ETag(`"v2"`))
+	assert.Nilf(t, err, "render err")
+	assert.Equalf(t, 200, w.Code, "streaming not conditional")
+	assert.Equalf(t, "[1]\n", w.Body.String(), "streaming body")
+	r.Header.Del(render.IfNoneMatchHeader)
+
+	r = httptest.NewRequest("POST", "/", nil)
+	r.Header.Set(render.IfNoneMatchHeader, "*")
+	w = httptest.NewRecorder()
+	err = render.JSON.OK(w, r, data, render.ETag(`"v1"`))
+	assert.Nilf(t, err, "render err")
+	assert.Equalf(t, 200, w.Code, "post ignored")
+	assert.Equalf(t, `"v1"`, w.Header().Get(render.ETagHeader), "etag")
+
+	w = httptest.NewRecorder()
+	err = render.JSON.Render(w, render.NewResponse(data, render.WithETag(`"v2"`)), render.ETag(`"v1"`))
+	assert.Nilf(t, err, "render err")
+	assert.Equalf(t, []string{`"v2"`}, w.Header().Values(render.ETagHeader), "single etag")
+}
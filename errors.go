@@ -0,0 +1,58 @@
+package render
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"github.com/ccmonky/errors"
+	"github.com/ccmonky/inithook"
+)
+
+// MetaErrors the registry of known `MetaError`s, key is the error code, it's used to document the error codes
+// (see package openapi) and to reconstruct the errors from responses(see package client), register the
+// application's errors to make them known, e.g.
+//
+//	render.MetaErrors.Register(ctx, ErrUserNotFound.Code(), ErrUserNotFound)
+var MetaErrors = inithook.NewMap[string, errors.MetaError]()
+
+// source of the render's meta errors
+const source = "render"
+
+var (
+	// NotAcceptable used when no content type is acceptable for the request(406)
+	NotAcceptable = errors.NewMetaError(source, "not_acceptable", "not acceptable", errors.WithStatus(http.StatusNotAcceptable))
+
+	// UnsupportedMediaType used when no decoder found for the request's content type(415)
+	UnsupportedMediaType = errors.NewMetaError(source, "unsupported_media_type", "unsupported media type", errors.WithStatus(http.StatusUnsupportedMediaType))
+
+	// MalformedBody used when the request body can not be decoded(400)
+	MalformedBody = errors.NewMetaError(source, "malformed_body", "malformed body", errors.WithStatus(http.StatusBadRequest))
+
+	// BodyTooLarge used when the request body exceeds the bind limit(413)
+	BodyTooLarge = errors.NewMetaError(source, "body_too_large", "body too large", errors.WithStatus(http.StatusRequestEntityTooLarge))
+
+	// ValidationFailed used when the request fails the validation, see `ValidationError`(422)
+	ValidationFailed = errors.NewMetaError(source, "validation_failed", "validation failed", errors.WithStatus(http.StatusUnprocessableEntity))
+)
+
+func init() {
+	ctx := context.Background()
+	var err error
+	for _, meta := range []errors.MetaError{
+		errors.OK,
+		errors.Unknown,
+		errors.InvalidArgument,
+		errors.NotFound,
+		NotAcceptable,
+		UnsupportedMediaType,
+		MalformedBody,
+		BodyTooLarge,
+		ValidationFailed,
+	} {
+		err = errors.WithError(err, MetaErrors.Register(ctx, meta.Code(), meta))
+	}
+	if err != nil {
+		log.Panicln(errors.GetAllErrors(err))
+	}
+}
@@ -0,0 +1,220 @@
+package render
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ccmonky/inithook"
+)
+
+// ExposureHeader custom `X-Render-Exposure` request header name, carries the signed exposure, see `SignExposure`
+const ExposureHeader = "X-Render-Exposure"
+
+// Exposure defines how much of the error detail is exposed by the envelope
+type Exposure int32
+
+const (
+	// ExposeDebug exposes the full error detail and all the attributes of `errors.Map`, it's the default
+	ExposeDebug Exposure = iota
+
+	// ExposeInternal exposes the full error detail and the attributes allowed for internal(see `ExposedAttrs`)
+	ExposeInternal
+
+	// ExposePublic replaces the error detail with an opaque incident id which is logged with the error,
+	// and exposes only the attributes allowed for public(see `ExposedAttrs`)
+	ExposePublic
+)
+
+// String implement `fmt.Stringer`
+func (e Exposure) String() string {
+	switch e {
+	case ExposeDebug:
+		return "debug"
+	case ExposeInternal:
+		return "internal"
+	case ExposePublic:
+		return "public"
+	}
+	return "exposure(" + strconv.Itoa(int(e)) + ")"
+}
+
+// ParseExposure parses the exposure name, i.e. debug, internal or public
+func ParseExposure(s string) (Exposure, error) {
+	switch strings.ToLower(s) {
+	case "debug":
+		return ExposeDebug, nil
+	case "internal":
+		return ExposeInternal, nil
+	case "public":
+		return ExposePublic, nil
+	}
+	return 0, fmt.Errorf("unknown exposure %q", s)
+}
+
+var (
+	// Exposures used to store the exposure of template, it overrides the global one(see `SetExposure`), e.g.
+	//
+	//	render.Exposures.Register(ctx, render.ProblemTemplate, render.ExposePublic)
+	Exposures = inithook.NewMap[string, Exposure]()
+
+	// ExposedAttrs the allowlist of `errors.Map` attributes, the value is the least verbose exposure the attribute
+	// is exposed with(see `Get`), e.g. `ExposePublic` means exposed with any exposure, the attributes not registered
+	// are only exposed with `ExposeDebug`
+	ExposedAttrs = inithook.NewMap[string, Exposure]()
+)
+
+// IncidentLogger logs the error hidden by `ExposePublic` with the incident id
+type IncidentLogger func(incident string, err error)
+
+// SetExposure sets the exposure of the default `Renderer`, default to `ExposeDebug`
+func SetExposure(e Exposure) {
+	defaultRenderer.SetExposure(e)
+}
+
+// WithExposure used to specify the exposure of `Response`, it overrides the exposures of request, template and renderer
+func WithExposure(e Exposure) ResponseOption {
+	return func(rp *Response) {
+		rp.exposure = &e
+	}
+}
+
+// ContextWithExposure returns the context carries the exposure of request
+func ContextWithExposure(ctx context.Context, e Exposure) context.Context {
+	return context.WithValue(ctx, exposureKey{}, e)
+}
+
+// ExposureFromContext returns the exposure carried by context
+func ExposureFromContext(ctx context.Context) (Exposure, bool) {
+	e, ok := ctx.Value(exposureKey{}).(Exposure)
+	return e, ok
+}
+
+// ExposureVerifier returns the exposure of request if trusted
+type ExposureVerifier func(r *http.Request) (Exposure, bool)
+
+// TrustExposure returns the middleware which sets the exposure of request(see `ContextWithExposure`) if verified,
+// it overrides the exposures of template and renderer, `ContentType.OK` and `ContentType.Err` use it, e.g.
+//
+//	handler = render.TrustExposure(render.SignedExposure(secret, time.Minute))(handler)
+func TrustExposure(verify ExposureVerifier) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if e, ok := verify(r); ok {
+				r = r.WithContext(ContextWithExposure(r.Context(), e))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// SignedExposure returns the `ExposureVerifier` which trusts the `ExposureHeader` signed by `SignExposure` with secret,
+// the signature expires after maxAge
+func SignedExposure(secret []byte, maxAge time.Duration) ExposureVerifier {
+	return func(r *http.Request) (Exposure, bool) {
+		parts := strings.Split(r.Header.Get(ExposureHeader), ":")
+		if len(parts) != 3 {
+			return 0, false
+		}
+		e, err := ParseExposure(parts[0])
+		if err != nil {
+			return 0, false
+		}
+		ts, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			return 0, false
+		}
+		if age := time.Since(time.Unix(ts, 0)); age > maxAge || age < -maxAge {
+			return 0, false
+		}
+		sig, err := hex.DecodeString(parts[2])
+		if err != nil || !hmac.Equal(sig, exposureSignature(secret, parts[0], parts[1])) {
+			return 0, false
+		}
+		return e, true
+	}
+}
+
+// SignExposure returns the value of `ExposureHeader` signed with secret at t, used by the trusted callers
+func SignExposure(secret []byte, e Exposure, t time.Time) string {
+	ts := strconv.FormatInt(t.Unix(), 10)
+	return e.String() + ":" + ts + ":" + hex.EncodeToString(exposureSignature(secret, e.String(), ts))
+}
+
+func exposureSignature(secret []byte, exposure, ts string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(exposure + ":" + ts))
+	return mac.Sum(nil)
+}
+
+// requestExposure used to specify the exposure of `Response` from the request context
+func requestExposure(r *http.Request) ResponseOption {
+	return func(rp *Response) {
+		if r == nil {
+			return
+		}
+		if e, ok := ExposureFromContext(r.Context()); ok && rp.exposure == nil {
+			rp.exposure = &e
+		}
+	}
+}
+
+// Exposure returns the exposure of rp: the one specified by `WithExposure` or request, the one of template,
+// or the one of renderer
+func (rp *Response) Exposure() Exposure {
+	if rp.exposure != nil {
+		return *rp.exposure
+	}
+	rd := rp.rendererOrDefault()
+	if e, err := rd.Exposures.Get(context.Background(), rp.Template); err == nil {
+		return e
+	}
+	return rd.Exposure()
+}
+
+// Detail returns the error detail according to the exposure, i.e. the incident id if the detail is hidden
+func (rp *Response) Detail() string {
+	if rp.Exposure() < ExposePublic {
+		return fmt.Sprint(rp.MetaError)
+	}
+	if !rp.failed() {
+		return ""
+	}
+	if rp.incident == "" {
+		rp.incident = newIncident()
+		rp.rendererOrDefault().logIncident(rp.incident, rp.MetaError)
+	}
+	return "incident:" + rp.incident
+}
+
+// exposed tells if the `errors.Map` attribute is exposed
+func (rp *Response) exposed(key string) bool {
+	e := rp.Exposure()
+	if e == ExposeDebug {
+		return true
+	}
+	least, err := rp.rendererOrDefault().ExposedAttrs.Get(context.Background(), key)
+	return err == nil && e <= least
+}
+
+func newIncident() string {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(b)
+}
+
+func logIncident(incident string, err error) {
+	log.Printf("render: incident %s: %v", incident, err)
+}
+
+type exposureKey struct{}
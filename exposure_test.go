@@ -0,0 +1,94 @@
+package render_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ccmonky/errors"
+	"github.com/ccmonky/render"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExposure(t *testing.T) {
+	var incidents []string
+	rd := render.New(
+		render.WithDefaultExposure(render.ExposePublic),
+		render.WithExposedAttr("status", render.ExposeInternal),
+		render.WithTemplateExposure("internal", render.ExposeInternal),
+		render.WithTransformer("internal", func(rp *render.Response) render.ResponseInterface { return rp }),
+		render.WithIncidentLogger(func(incident string, err error) {
+			incidents = append(incidents, incident+" "+err.Error())
+		}),
+	)
+	err := errors.WithError(errors.New("db password is xxx"), errors.NotFound)
+
+	rp := rd.NewResponse(nil, render.E(err)).(*render.Response)
+	assert.Equalf(t, render.ExposePublic, rp.Exposure(), "default exposure")
+	detail := rp.Detail()
+	assert.Truef(t, strings.HasPrefix(detail, "incident:"), "incident detail: %s", detail)
+	assert.Equalf(t, detail, rp.Detail(), "incident generated once")
+	assert.Equalf(t, detail, rp.Header().Get("X-Detail"), "header detail")
+	assert.Equalf(t, 1, len(incidents), "incident logged once")
+	assert.Truef(t, strings.Contains(incidents[0], strings.TrimPrefix(detail, "incident:")), "logged incident id")
+	assert.Truef(t, strings.Contains(incidents[0], "db password is xxx"), "logged error")
+	_, ok := render.Get(rp, "status")
+	assert.Falsef(t, ok, "status hidden for public")
+
+	rp = rd.NewResponse(nil, render.E(err), render.T("internal")).(*render.Response)
+	assert.Equalf(t, render.ExposeInternal, rp.Exposure(), "template exposure")
+	assert.Truef(t, strings.HasPrefix(rp.Detail(), "db password is xxx"), "internal detail: %s", rp.Detail())
+	status, ok := render.Get(rp, "status")
+	assert.Truef(t, ok, "status exposed for internal")
+	assert.Equalf(t, 404, status, "status")
+
+	rp = rd.NewResponse(nil, render.E(err), render.WithExposure(render.ExposeDebug)).(*render.Response)
+	assert.Equalf(t, render.ExposeDebug, rp.Exposure(), "response exposure")
+	_, ok = render.Get(rp, "status")
+	assert.Truef(t, ok, "all attrs exposed for debug")
+
+	rp = rd.NewResponse("data").(*render.Response)
+	assert.Equalf(t, "", rp.Detail(), "no incident for ok")
+	assert.Equalf(t, 1, len(incidents), "ok not logged")
+
+	problem := rd.NewResponse(nil, render.E(err), render.T(render.ProblemTemplate)).Body().(map[string]any)
+	assert.Truef(t, strings.HasPrefix(problem["detail"].(string), "incident:"), "problem detail")
+}
+
+func TestParseExposure(t *testing.T) {
+	for _, e := range []render.Exposure{render.ExposeDebug, render.ExposeInternal, render.ExposePublic} {
+		parsed, err := render.ParseExposure(e.String())
+		assert.Nilf(t, err, "parse %s", e)
+		assert.Equalf(t, e, parsed, "parse %s", e)
+	}
+	_, err := render.ParseExposure("secret")
+	assert.NotNilf(t, err, "unknown exposure")
+}
+
+func TestSignedExposure(t *testing.T) {
+	secret := []byte("secret")
+	rd := render.New(render.WithDefaultExposure(render.ExposePublic))
+	handler := render.TrustExposure(render.SignedExposure(secret, time.Minute))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rd.Err(w, r, render.JSON, errors.WithError(errors.New("stack trace"), errors.Unknown))
+	}))
+	detail := func(header string) string {
+		r := httptest.NewRequest("GET", "/", nil)
+		if header != "" {
+			r.Header.Set(render.ExposureHeader, header)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		var body map[string]any
+		assert.Nilf(t, json.Unmarshal(w.Body.Bytes(), &body), "unmarshal")
+		return body["detail"].(string)
+	}
+
+	assert.Truef(t, strings.HasPrefix(detail(""), "incident:"), "unsigned")
+	assert.Truef(t, strings.HasPrefix(detail(render.SignExposure(secret, render.ExposeDebug, time.Now())), "stack trace"), "signed")
+	assert.Truef(t, strings.HasPrefix(detail(render.SignExposure([]byte("forged"), render.ExposeDebug, time.Now())), "incident:"), "forged")
+	assert.Truef(t, strings.HasPrefix(detail(render.SignExposure(secret, render.ExposeDebug, time.Now().Add(-time.Hour))), "incident:"), "expired")
+	assert.Truef(t, strings.HasPrefix(detail("debug:0:00"), "incident:"), "malformed")
+}
@@ -0,0 +1,160 @@
+package render
+
+import (
+	"fmt"
+	"mime/multipart"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// mapForm maps the form values and files into v, v can be `*url.Values`, `*map[string][]string`,
+// `*map[string]string` or pointer to struct, the struct fields are matched by `form` tag or field name,
+// `-` tag skips the field, embedded structs are flattened.
+func mapForm(values url.Values, files map[string][]*multipart.FileHeader, v any) error {
+	switch v := v.(type) {
+	case *url.Values:
+		*v = values
+		return nil
+	case *map[string][]string:
+		*v = values
+		return nil
+	case *map[string]string:
+		m := make(map[string]string, len(values))
+		for k := range values {
+			m[k] = values.Get(k)
+		}
+		*v = m
+		return nil
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("form binding target should be a non-nil pointer to struct, but got %T", v)
+	}
+	return mapStruct(values, files, rv.Elem())
+}
+
+func mapStruct(values url.Values, files map[string][]*multipart.FileHeader, rv reflect.Value) error {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		fv := rv.Field(i)
+		name := field.Tag.Get("form")
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" && fv.Kind() == reflect.Struct {
+			if err := mapStruct(values, files, fv); err != nil {
+				return err
+			}
+			continue
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		switch fv.Type() {
+		case fileHeaderType:
+			if fhs := files[name]; len(fhs) > 0 {
+				fv.Set(reflect.ValueOf(fhs[0]))
+			}
+			continue
+		case fileHeadersType:
+			if fhs := files[name]; len(fhs) > 0 {
+				fv.Set(reflect.ValueOf(fhs))
+			}
+			continue
+		}
+		vs, ok := values[name]
+		if !ok || len(vs) == 0 {
+			continue
+		}
+		if err := setField(fv, vs); err != nil {
+			return fmt.Errorf("form field %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func setField(fv reflect.Value, vs []string) error {
+	if fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() != reflect.Uint8 {
+		slice := reflect.MakeSlice(fv.Type(), len(vs), len(vs))
+		for i, s := range vs {
+			if err := setValue(slice.Index(i), s); err != nil {
+				return err
+			}
+		}
+		fv.Set(slice)
+		return nil
+	}
+	if fv.Kind() == reflect.Ptr {
+		if fv.IsNil() {
+			fv.Set(reflect.New(fv.Type().Elem()))
+		}
+		return setValue(fv.Elem(), vs[0])
+	}
+	return setValue(fv, vs[0])
+}
+
+func setValue(fv reflect.Value, s string) error {
+	if fv.Type() == timeType {
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(t))
+		return nil
+	}
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(strings.TrimSpace(s))
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if fv.Type() == durationType {
+			d, err := time.ParseDuration(s)
+			if err != nil {
+				return err
+			}
+			fv.SetInt(int64(d))
+			return nil
+		}
+		i, err := strconv.ParseInt(strings.TrimSpace(s), 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(strings.TrimSpace(s), 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(strings.TrimSpace(s), fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(f)
+	case reflect.Slice:
+		fv.SetBytes([]byte(s))
+	default:
+		return fmt.Errorf("unsupported type %v", fv.Type())
+	}
+	return nil
+}
+
+var (
+	fileHeaderType  = reflect.TypeOf((*multipart.FileHeader)(nil))
+	fileHeadersType = reflect.TypeOf([]*multipart.FileHeader(nil))
+	timeType        = reflect.TypeOf(time.Time{})
+	durationType    = reflect.TypeOf(time.Duration(0))
+)
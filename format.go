@@ -0,0 +1,121 @@
+package render
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/ccmonky/errors"
+)
+
+// FormatQuery used to specify the query parameter of format, empty disables it, default to `format`
+func FormatQuery(name string) Option {
+	return func(o any) {
+		if options, ok := o.(*formatOptions); ok {
+			options.Query = name
+		}
+	}
+}
+
+// FormatSuffix used to specify whether the path suffix(e.g. `/items.xml`) is used as format, default to true
+func FormatSuffix(enabled bool) Option {
+	return func(o any) {
+		if options, ok := o.(*formatOptions); ok {
+			options.Suffix = enabled
+		}
+	}
+}
+
+type formatOptions struct {
+	Query  string
+	Suffix bool
+}
+
+// Format returns the middleware which resolves the response format from the known path suffix(e.g. `GET /items.xml`)
+// or the query parameter(e.g. `GET /items?format=yaml`) with the names of `ContentTypes`, see `Renderer.Format`
+func Format(opts ...Option) func(http.Handler) http.Handler {
+	return defaultRenderer.Format(opts...)
+}
+
+// ContextWithFormat returns the context carries the content type resolved from format, it's used by `Negotiate`
+// instead of the `Accept` header
+func ContextWithFormat(ctx context.Context, ct ContentType) context.Context {
+	return context.WithValue(ctx, formatKey{}, ct)
+}
+
+// FormatFromContext returns the content type resolved from format
+func FormatFromContext(ctx context.Context) (ContentType, bool) {
+	ct, ok := ctx.Value(formatKey{}).(ContentType)
+	return ct, ok
+}
+
+// Format returns the middleware which resolves the response format from the path suffix or the query parameter
+// with the names of rd's `ContentTypes`, the query parameter takes precedence. The used suffix is stripped from
+// the path before routing, and the resolved content type is stored in request context(see `ContextWithFormat`),
+// so `Negotiate` returns it instead of negotiating `Accept` header, which is still used if no format specified.
+// Only the suffix known by `ContentTypes` and ready to render is used as format, others(e.g. `/users/john.doe`,
+// `/static/app.js`) are left untouched, while an unknown query format(or one without render) is rejected with
+// a 406 envelope listing the supported content types.
+func (rd *Renderer) Format(opts ...Option) func(http.Handler) http.Handler {
+	options := formatOptions{
+		Query:  "format",
+		Suffix: true,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var (
+				ct       ContentType
+				stripped string
+			)
+			format := ""
+			if options.Query != "" {
+				format = r.URL.Query().Get(options.Query)
+			}
+			if format != "" {
+				c, err := rd.ContentType(format)
+				if err == nil && !rd.Ready(c) {
+					err = fmt.Errorf("render not found for %v", c)
+				}
+				if err != nil {
+					rd.reject(w, r, errors.WithError(errors.WithMessagef(err, "unknown format %q", format), NotAcceptable))
+					return
+				}
+				ct = c
+			} else if options.Suffix {
+				// NOTE: only the known suffix is the format, e.g. `/users/john.doe` is untouched
+				if suffix, p := pathFormat(r.URL.Path); suffix != "" {
+					if c, err := rd.ContentType(suffix); err == nil && rd.Ready(c) {
+						ct, stripped = c, p
+					}
+				}
+			}
+			if ct == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			r = r.WithContext(ContextWithFormat(r.Context(), ct))
+			if stripped != "" {
+				u := *r.URL
+				u.Path, u.RawPath = stripped, ""
+				r.URL = &u
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// pathFormat returns the format suffix of the last path segment and the path without it
+func pathFormat(p string) (format, stripped string) {
+	ext := path.Ext(p)
+	if len(ext) <= 1 || strings.ContainsRune(ext, '/') {
+		return "", ""
+	}
+	return ext[1:], strings.TrimSuffix(p, ext)
+}
+
+type formatKey struct{}
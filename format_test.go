@@ -0,0 +1,75 @@
+package render_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ccmonky/render"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormat(t *testing.T) {
+	var (
+		path string
+		ct   render.ContentType
+	)
+	handler := render.Format()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		ct = render.Negotiate(r)
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/items.problem", nil))
+	assert.Equalf(t, "/items", path, "suffix stripped")
+	assert.Equalf(t, render.ProblemJSON, ct, "suffix format")
+
+	r := httptest.NewRequest("GET", "/items?format=ndjson", nil)
+	r.Header.Set("Accept", "application/json")
+	handler.ServeHTTP(w, r)
+	assert.Equalf(t, "/items", path, "query path")
+	assert.Equalf(t, render.NDJSON, ct, "query format")
+
+	r = httptest.NewRequest("GET", "/v1.0/items", nil)
+	r.Header.Set("Accept", "application/problem+json")
+	handler.ServeHTTP(w, r)
+	assert.Equalf(t, "/v1.0/items", path, "no suffix")
+	assert.Equalf(t, render.ProblemJSON, ct, "fallback to accept")
+
+	for _, p := range []string{"/users/john.doe", "/files/report.v2", "/static/app.js", "/items.yaml"} {
+		w = httptest.NewRecorder()
+		r = httptest.NewRequest("GET", p, nil)
+		r.Header.Set("Accept", "application/problem+json")
+		handler.ServeHTTP(w, r)
+		assert.Equalf(t, 200, w.Code, "dotted %s not rejected", p)
+		assert.Equalf(t, p, path, "dotted %s untouched", p)
+		assert.Equalf(t, render.ProblemJSON, ct, "dotted %s negotiated by accept", p)
+	}
+
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/items.json?format=problem", nil))
+	assert.Equalf(t, "/items.json", path, "unused suffix not stripped")
+	assert.Equalf(t, render.ProblemJSON, ct, "query wins")
+
+	path, ct = "", ""
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/items?format=foo", nil))
+	assert.Equalf(t, "", path, "rejected")
+	assert.Equalf(t, 406, w.Code, "unknown format status")
+	assert.Equalf(t, "not_acceptable", w.Header().Get("X-Code"), "unknown format code")
+	var body map[string]any
+	json.Unmarshal(w.Body.Bytes(), &body)
+	assert.Containsf(t, body["data"].(map[string]any)["supported"], string(render.JSON), "supported")
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/items?format=yaml", nil))
+	assert.Equalf(t, 406, w.Code, "format without render")
+
+	handler = render.Format(render.FormatSuffix(false), render.FormatQuery("fmt"))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		ct = render.Negotiate(r)
+	}))
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/items.foo?fmt=problem", nil))
+	assert.Equalf(t, "/items.foo", path, "suffix disabled")
+	assert.Equalf(t, render.ProblemJSON, ct, "custom query")
+}
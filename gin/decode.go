@@ -0,0 +1,31 @@
+package gin
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	"github.com/ccmonky/errors"
+	"github.com/ccmonky/render"
+	"github.com/ugorji/go/codec"
+	"gopkg.in/yaml.v2"
+)
+
+func init() {
+	ctx := context.Background()
+	err := render.Decoders.Register(ctx, render.YAML, render.DecoderFunc(DecodeYAML))
+	err = errors.WithError(err, render.Decoders.Register(ctx, render.MSGPACK, render.DecoderFunc(DecodeMsgPack)))
+	if err != nil {
+		log.Panicln(errors.GetAllErrors(err))
+	}
+}
+
+// DecodeYAML decodes the yaml request body into v
+func DecodeYAML(r *http.Request, v any) error {
+	return yaml.NewDecoder(r.Body).Decode(v)
+}
+
+// DecodeMsgPack decodes the msgpack request body into v
+func DecodeMsgPack(r *http.Request, v any) error {
+	return codec.NewDecoder(r.Body, new(codec.MsgpackHandle)).Decode(v)
+}
@@ -0,0 +1,31 @@
+package gin_test
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ccmonky/render"
+	"github.com/stretchr/testify/assert"
+	"github.com/ugorji/go/codec"
+)
+
+func TestDecode(t *testing.T) {
+	type user struct {
+		Name string `yaml:"name" codec:"name"`
+	}
+	r := httptest.NewRequest("POST", "/", strings.NewReader("name: a\n"))
+	r.Header.Set(render.ContentTypeHeader, "application/x-yaml")
+	var u user
+	err := render.Bind(r, &u)
+	assert.Nilf(t, err, "yaml err")
+	assert.Equalf(t, "a", u.Name, "yaml")
+
+	var body []byte
+	codec.NewEncoderBytes(&body, new(codec.MsgpackHandle)).Encode(map[string]string{"name": "b"})
+	r = httptest.NewRequest("POST", "/", strings.NewReader(string(body)))
+	r.Header.Set(render.ContentTypeHeader, "application/msgpack")
+	err = render.Bind(r, &u)
+	assert.Nilf(t, err, "msgpack err")
+	assert.Equalf(t, "b", u.Name, "msgpack")
+}
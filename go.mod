@@ -7,9 +7,10 @@ require (
 	github.com/ccmonky/inithook v0.0.0-20230122023823-e4bfffdc359b
 	github.com/gin-gonic/gin v1.8.2
 	github.com/stretchr/testify v1.8.1
-	github.com/timewasted/go-accept-headers v0.0.0-20130320203746-c78f304b1b09
+	github.com/ugorji/go/codec v1.2.7
 	github.com/unrolled/render v1.5.0
 	go.uber.org/atomic v1.10.0
+	gopkg.in/yaml.v2 v2.4.0
 )
 
 require (
@@ -23,9 +24,7 @@ require (
 	github.com/pelletier/go-toml/v2 v2.0.6 // indirect
 	github.com/pkg/errors v0.9.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	github.com/ugorji/go/codec v1.2.7 // indirect
 	golang.org/x/sys v0.3.0 // indirect
 	google.golang.org/protobuf v1.28.1 // indirect
-	gopkg.in/yaml.v2 v2.4.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,44 @@
+package render
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/ccmonky/errors"
+)
+
+// Handle adapts fn to `http.Handler`, see `HandlerFunc`
+func Handle[T any](fn func(*http.Request) (T, error), opts ...Option) http.Handler {
+	return HandlerFunc(fn, opts...)
+}
+
+// HandlerFunc adapts fn to `http.HandlerFunc`, the content type is negotiated by `NegotiateOrReject`,
+// then the data is rendered by `ContentType.OK` if err is nil, otherwise err is rendered by `ContentType.Err`,
+// the template is selected by `TemplateHeader` of request. A panic of fn is recovered and rendered as `errors.Unknown`.
+func HandlerFunc[T any](fn func(*http.Request) (T, error), opts ...Option) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ct, ok := NegotiateOrReject(w, r)
+		if !ok {
+			return
+		}
+		data, err := call(fn, r)
+		if err != nil {
+			ct.Err(w, r, err, opts...)
+			return
+		}
+		ct.OK(w, r, data, opts...)
+	}
+}
+
+// call calls fn and converts the panic of fn(except `http.ErrAbortHandler`) into an `errors.Unknown` error
+func call[T any](fn func(*http.Request) (T, error), r *http.Request) (data T, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			if p == http.ErrAbortHandler {
+				panic(p)
+			}
+			err = errors.WithError(fmt.Errorf("panic: %v", p), errors.Unknown)
+		}
+	}()
+	return fn(r)
+}
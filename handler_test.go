@@ -0,0 +1,71 @@
+package render_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ccmonky/errors"
+	"github.com/ccmonky/render"
+	"github.com/stretchr/testify/assert"
+)
+
+type panicMarshaler struct{}
+
+func (panicMarshaler) MarshalJSON() ([]byte, error) {
+	panic("marshal")
+}
+
+func TestHandle(t *testing.T) {
+	type user struct {
+		Name string `json:"name"`
+	}
+	h := render.Handle(func(r *http.Request) (*user, error) {
+		switch r.URL.Path {
+		case "/ok":
+			return &user{Name: "a"}, nil
+		case "/panic":
+			panic("boom")
+		default:
+			return nil, errors.NotFound
+		}
+	})
+
+	r := httptest.NewRequest("GET", "/ok", nil)
+	r.Header.Set(render.TemplateHeader, "no_timestamp")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	assert.Equalf(t, 200, w.Code, "status")
+	assert.Equalf(t, "no_timestamp", w.Header().Get(render.TemplateHeader), "template")
+	assert.Containsf(t, w.Body.String(), `"data":{"name":"a"}`, "body")
+
+	r = httptest.NewRequest("GET", "/missing", nil)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	assert.Equalf(t, 404, w.Code, "status")
+	assert.Equalf(t, "not_found(5)", w.Header().Get("X-Code"), "x-code")
+
+	r = httptest.NewRequest("GET", "/panic", nil)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	assert.Equalf(t, 500, w.Code, "status")
+	assert.Equalf(t, "unknown(2)", w.Header().Get("X-Code"), "x-code")
+	assert.Containsf(t, w.Header().Get("X-Detail"), "panic: boom", "x-detail")
+
+	hp := render.Handle(func(r *http.Request) (panicMarshaler, error) {
+		return panicMarshaler{}, nil
+	})
+	w = httptest.NewRecorder()
+	assert.PanicsWithValuef(t, "marshal", func() {
+		hp.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	}, "render panic not recovered")
+	assert.Equalf(t, 0, w.Body.Len(), "rendered nothing")
+
+	render.SetNegotiatePolicy(render.Strict)
+	defer render.SetNegotiatePolicy(render.Lenient)
+	r = httptest.NewRequest("GET", "/ok", nil)
+	r.Header.Set(render.AcceptHeader, "image/png")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	assert.Equalf(t, 406, w.Code, "status")
+}
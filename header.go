@@ -0,0 +1,164 @@
+package render
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ccmonky/inithook"
+)
+
+// the keys of the meta headers written by `Response.Header`
+const (
+	MetaApp     = "app"
+	MetaVersion = "version"
+	MetaCode    = "code"
+	MetaMessage = "message"
+	MetaDetail  = "detail"
+)
+
+const (
+	// DefaultHeaderPrefix the default prefix of the meta header names, e.g. `X-Code`
+	DefaultHeaderPrefix = "X-"
+
+	// DefaultHeaderMaxLength the default max length of the meta header values
+	DefaultHeaderMaxLength = 1024
+
+	// TruncationMarker appended to the truncated header value
+	TruncationMarker = "..."
+
+	// extValuePrefix the charset and language prefix of RFC 8187 ext-value
+	extValuePrefix = "UTF-8''"
+)
+
+var (
+	// HeaderNames used to store the header name of meta key(e.g. `MetaCode`), it overrides the prefixed one, e.g.
+	//
+	//	render.HeaderNames.Register(ctx, render.MetaCode, "Grpc-Status")
+	HeaderNames = inithook.NewMap[string, string]()
+
+	// DisabledHeaders used to store the meta keys whose headers are switched off for template, e.g.
+	//
+	//	render.DisabledHeaders.Register(ctx, render.ProblemTemplate, []string{render.MetaDetail})
+	DisabledHeaders = inithook.NewMap[string, []string]()
+)
+
+// SetHeaderPrefix sets the prefix of the meta header names of the default `Renderer`, default to `X-`
+func SetHeaderPrefix(prefix string) {
+	defaultRenderer.SetHeaderPrefix(prefix)
+}
+
+// SetHeaderMaxLength sets the max length of the meta header values of the default `Renderer`, 0 means unlimited
+func SetHeaderMaxLength(n int) {
+	defaultRenderer.SetHeaderMaxLength(n)
+}
+
+// SanitizeHeaderValue returns the valid header value of v: the control characters(e.g. CR, LF) are replaced by space,
+// the value with non-ASCII characters is encoded as RFC 8187 ext-value, and the value longer than max(0 means unlimited)
+// is truncated with the `TruncationMarker`, e.g.
+//
+//	render.SanitizeHeaderValue("5€ off", 0) // UTF-8''5%E2%82%AC%20off
+func SanitizeHeaderValue(v string, max int) string {
+	ascii := true
+	v = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return ' '
+		}
+		if r >= utf8.RuneSelf {
+			ascii = false
+		}
+		return r
+	}, v)
+	if !ascii {
+		v = extValuePrefix + encodeExtValue(v)
+	}
+	if max <= 0 || len(v) <= max {
+		return v
+	}
+	n := max - len(TruncationMarker)
+	if n < 0 {
+		n = 0
+	}
+	if !ascii {
+		// NOTE: not cut in the middle of the percent encoded octet
+		if i := strings.LastIndexByte(v[:n], '%'); i >= 0 && i+3 > n {
+			n = i
+		}
+		if n < len(extValuePrefix) {
+			n = len(extValuePrefix)
+		}
+	}
+	return v[:n] + TruncationMarker
+}
+
+// DecodeHeaderValue decodes the header value encoded by `SanitizeHeaderValue`, the invalid one is returned as is
+func DecodeHeaderValue(v string) string {
+	if !strings.HasPrefix(v, extValuePrefix) {
+		return v
+	}
+	decoded, err := url.PathUnescape(v[len(extValuePrefix):])
+	if err != nil {
+		return v
+	}
+	return decoded
+}
+
+// encodeExtValue percent encodes the octets which are not RFC 8187 attr-char
+func encodeExtValue(v string) string {
+	const hex = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(v); i++ {
+		c := v[i]
+		if isAttrChar(c) {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(hex[c>>4])
+		b.WriteByte(hex[c&0xf])
+	}
+	return b.String()
+}
+
+func isAttrChar(c byte) bool {
+	if 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' {
+		return true
+	}
+	return strings.IndexByte("!#$&+-.^_`|~", c) >= 0
+}
+
+// HeaderName returns the header name of meta key, the one registered in `HeaderNames`, or the prefixed one
+func (rd *Renderer) HeaderName(key string) string {
+	if name, err := rd.HeaderNames.Get(context.Background(), key); err == nil {
+		return name
+	}
+	return http.CanonicalHeaderKey(rd.headerPrefix.Load() + key)
+}
+
+// SetHeaderPrefix sets the prefix of the meta header names
+func (rd *Renderer) SetHeaderPrefix(prefix string) {
+	rd.headerPrefix.Store(prefix)
+}
+
+// SetHeaderMaxLength sets the max length of the meta header values, 0 means unlimited
+func (rd *Renderer) SetHeaderMaxLength(n int) {
+	rd.headerMaxLength.Store(int32(n))
+}
+
+// HeaderMaxLength returns the max length of the meta header values
+func (rd *Renderer) HeaderMaxLength() int {
+	return int(rd.headerMaxLength.Load())
+}
+
+// setMetaHeader sets the sanitized meta header unless it's switched off for template
+func (rd *Renderer) setMetaHeader(header http.Header, tmpl, key, value string) {
+	disabled, _ := rd.DisabledHeaders.Get(context.Background(), tmpl)
+	for _, k := range disabled {
+		if k == key {
+			return
+		}
+	}
+	header.Set(rd.HeaderName(key), SanitizeHeaderValue(value, rd.HeaderMaxLength()))
+}
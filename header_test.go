@@ -0,0 +1,65 @@
+package render_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ccmonky/errors"
+	"github.com/ccmonky/render"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSanitizeHeaderValue(t *testing.T) {
+	cases := []struct {
+		value    string
+		max      int
+		expected string
+	}{
+		{"not found", 0, "not found"},
+		{"line1\r\nline2\tx", 0, "line1  line2 x"},
+		{"5€ off", 0, "UTF-8''5%E2%82%AC%20off"},
+		{"abcdefghij", 8, "abcde..."},
+		{"abc", 3, "abc"},
+		{"€€€", 20, "UTF-8''%E2%82%AC..."},
+		{"€€€", 19, "UTF-8''%E2%82%AC..."},
+		{"€€€", 18, "UTF-8''%E2%82..."},
+	}
+	for _, c := range cases {
+		got := render.SanitizeHeaderValue(c.value, c.max)
+		assert.Equalf(t, c.expected, got, "sanitize %q by %d", c.value, c.max)
+		if c.max > 0 {
+			assert.LessOrEqualf(t, len(got), c.max, "length of %q", got)
+		}
+	}
+	assert.Equalf(t, "5€ off", render.DecodeHeaderValue("UTF-8''5%E2%82%AC%20off"), "decode")
+	assert.Equalf(t, "plain", render.DecodeHeaderValue("plain"), "decode plain")
+	assert.Equalf(t, "UTF-8''%zz", render.DecodeHeaderValue("UTF-8''%zz"), "decode invalid")
+}
+
+func TestMetaHeaders(t *testing.T) {
+	rd := render.New(
+		render.WithHeaderPrefix("X-Api-"),
+		render.WithHeaderName(render.MetaCode, "X-Error-Code"),
+		render.WithHeaderMaxLength(32),
+		render.WithDisabledHeaders(render.ProblemTemplate, render.MetaDetail, render.MetaVersion),
+	)
+	err := errors.WithError(errors.New(strings.Repeat("stack\nframe ", 10)), errors.Unknown)
+
+	header := rd.NewResponse(nil, render.E(err)).Header()
+	assert.Equalf(t, "unknown(2)", header.Get("X-Error-Code"), "renamed code")
+	assert.Equalf(t, "", header.Get("X-Code"), "default code name")
+	assert.Equalf(t, "unknown", header.Get("X-Api-Message"), "prefixed message")
+	detail := header.Get("X-Api-Detail")
+	assert.Equalf(t, 32, len(detail), "truncated detail")
+	assert.Truef(t, strings.HasSuffix(detail, render.TruncationMarker), "truncation marker")
+	assert.Falsef(t, strings.ContainsAny(detail, "\r\n"), "no CR/LF")
+	_, ok := header["X-Api-Version"]
+	assert.Truef(t, ok, "version header")
+
+	header = rd.NewResponse(nil, render.E(err), render.T(render.ProblemTemplate)).Header()
+	_, ok = header["X-Api-Detail"]
+	assert.Falsef(t, ok, "detail switched off")
+	_, ok = header["X-Api-Version"]
+	assert.Falsef(t, ok, "version switched off")
+	assert.Equalf(t, "unknown", header.Get("X-Api-Message"), "message of problem")
+}
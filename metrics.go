@@ -0,0 +1,244 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// MetricsContentType the content type of Prometheus text exposition format
+const MetricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+var (
+	// DefaultDurationBuckets the default buckets of the render duration histogram, in seconds
+	DefaultDurationBuckets = []float64{0.0001, 0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1}
+
+	// DefaultBytesBuckets the default buckets of the response bytes histogram
+	DefaultBytesBuckets = []float64{64, 256, 1024, 4096, 16384, 65536, 262144, 1048576}
+)
+
+// MetricsNamespace used to specify the prefix of the metric names, default to `render`
+func MetricsNamespace(namespace string) Option {
+	return func(o any) {
+		if options, ok := o.(*metricsOptions); ok {
+			options.Namespace = namespace
+		}
+	}
+}
+
+// DurationBuckets used to specify the upper bounds(in seconds) of the render duration histogram buckets
+func DurationBuckets(buckets ...float64) Option {
+	return func(o any) {
+		if options, ok := o.(*metricsOptions); ok {
+			options.DurationBuckets = buckets
+		}
+	}
+}
+
+// BytesBuckets used to specify the upper bounds of the response bytes histogram buckets
+func BytesBuckets(buckets ...float64) Option {
+	return func(o any) {
+		if options, ok := o.(*metricsOptions); ok {
+			options.BytesBuckets = buckets
+		}
+	}
+}
+
+type metricsOptions struct {
+	Namespace       string
+	DurationBuckets []float64
+	BytesBuckets    []float64
+}
+
+// Metrics is an in-process `Observer` collecting the render metrics, and an `http.Handler` exposing them
+// in Prometheus text exposition format, the metrics are(with the default namespace):
+//
+//   - render_responses_total{content_type,template,status,code}: counter of the rendered responses
+//   - render_errors_total{content_type}: counter of the render errors, e.g. encode errors
+//   - render_duration_seconds{content_type}: histogram of the render durations
+//   - render_response_bytes{content_type}: histogram of the body bytes written
+//
+// the `content_type` label is the media type without parameters.
+type Metrics struct {
+	mu        sync.Mutex
+	responses map[string]float64 // NOTE: key is the encoded labels
+	errors    map[string]float64
+	durations map[string]*histogram
+	bytes     map[string]*histogram
+	options   metricsOptions
+}
+
+// NewMetrics creates a new `Metrics`
+func NewMetrics(opts ...Option) *Metrics {
+	options := metricsOptions{
+		Namespace:       "render",
+		DurationBuckets: DefaultDurationBuckets,
+		BytesBuckets:    DefaultBytesBuckets,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	options.DurationBuckets = sortedBuckets(options.DurationBuckets)
+	options.BytesBuckets = sortedBuckets(options.BytesBuckets)
+	return &Metrics{
+		responses: make(map[string]float64),
+		errors:    make(map[string]float64),
+		durations: make(map[string]*histogram),
+		bytes:     make(map[string]*histogram),
+		options:   options,
+	}
+}
+
+// ObserveRender implement `Observer`
+func (m *Metrics) ObserveRender(event RenderEvent) {
+	ct := ParseMediaType(string(event.ContentType)).String()
+	ctLabels := encodeLabels("content_type", ct)
+	responseLabels := encodeLabels(
+		"content_type", ct,
+		"template", event.Template,
+		"status", strconv.Itoa(event.Status),
+		"code", event.Code,
+	)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.responses[responseLabels]++
+	if event.Err != nil {
+		m.errors[ctLabels]++
+	}
+	m.histogram(m.durations, ctLabels, m.options.DurationBuckets).observe(event.Duration.Seconds())
+	m.histogram(m.bytes, ctLabels, m.options.BytesBuckets).observe(float64(event.Bytes))
+}
+
+// ServeHTTP implement `http.Handler`, writes the metrics in Prometheus text exposition format
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set(ContentTypeHeader, MetricsContentType)
+	w.Write(m.Text())
+}
+
+// Text returns the metrics in Prometheus text exposition format
+func (m *Metrics) Text() []byte {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var buf bytes.Buffer
+	writeCounter(&buf, m.name("responses_total"), "The number of rendered responses.", m.responses)
+	writeCounter(&buf, m.name("errors_total"), "The number of render errors.", m.errors)
+	writeHistogram(&buf, m.name("duration_seconds"), "The render duration in seconds.", m.durations)
+	writeHistogram(&buf, m.name("response_bytes"), "The number of body bytes written.", m.bytes)
+	return buf.Bytes()
+}
+
+// Reset clears the collected metrics
+func (m *Metrics) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.responses = make(map[string]float64)
+	m.errors = make(map[string]float64)
+	m.durations = make(map[string]*histogram)
+	m.bytes = make(map[string]*histogram)
+}
+
+func (m *Metrics) name(name string) string {
+	if m.options.Namespace == "" {
+		return name
+	}
+	return m.options.Namespace + "_" + name
+}
+
+func (m *Metrics) histogram(hs map[string]*histogram, labels string, buckets []float64) *histogram {
+	h, ok := hs[labels]
+	if !ok {
+		h = &histogram{
+			buckets: buckets,
+			counts:  make([]uint64, len(buckets)),
+		}
+		hs[labels] = h
+	}
+	return h
+}
+
+// histogram the non-cumulative bucket counts, the count of `+Inf` is `count`
+type histogram struct {
+	buckets []float64
+	counts  []uint64
+	count   uint64
+	sum     float64
+}
+
+func (h *histogram) observe(v float64) {
+	if i := sort.SearchFloat64s(h.buckets, v); i < len(h.buckets) {
+		h.counts[i]++
+	}
+	h.count++
+	h.sum += v
+}
+
+func writeCounter(buf *bytes.Buffer, name, help string, series map[string]float64) {
+	fmt.Fprintf(buf, "# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+	for _, labels := range sortedKeys(series) {
+		fmt.Fprintf(buf, "%s{%s} %s\n", name, labels, formatFloat(series[labels]))
+	}
+}
+
+func writeHistogram(buf *bytes.Buffer, name, help string, series map[string]*histogram) {
+	fmt.Fprintf(buf, "# HELP %s %s\n# TYPE %s histogram\n", name, help, name)
+	for _, labels := range sortedKeys(series) {
+		h := series[labels]
+		var cumulative uint64
+		for i, bound := range h.buckets {
+			cumulative += h.counts[i]
+			fmt.Fprintf(buf, "%s_bucket{%s,le=\"%s\"} %d\n", name, labels, formatFloat(bound), cumulative)
+		}
+		fmt.Fprintf(buf, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, labels, h.count)
+		fmt.Fprintf(buf, "%s_sum{%s} %s\n", name, labels, formatFloat(h.sum))
+		fmt.Fprintf(buf, "%s_count{%s} %d\n", name, labels, h.count)
+	}
+}
+
+// encodeLabels encodes the label pairs as `k1="v1",k2="v2"` with the values escaped
+func encodeLabels(kvs ...string) string {
+	var b strings.Builder
+	for i := 0; i+1 < len(kvs); i += 2 {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(kvs[i])
+		b.WriteString(`="`)
+		b.WriteString(labelValueEscaper.Replace(kvs[i+1]))
+		b.WriteByte('"')
+	}
+	return b.String()
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatFloat(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedBuckets(buckets []float64) []float64 {
+	sorted := append([]float64(nil), buckets...)
+	sort.Float64s(sorted)
+	return sorted
+}
+
+var (
+	_ Observer     = (*Metrics)(nil)
+	_ http.Handler = (*Metrics)(nil)
+)
@@ -0,0 +1,59 @@
+package render_test
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ccmonky/render"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	metrics := render.NewMetrics(render.DurationBuckets(0.1, 0.01), render.BytesBuckets(100))
+	metrics.ObserveRender(render.RenderEvent{
+		ContentType: render.JSON,
+		Status:      200,
+		Code:        "success(0)",
+		Bytes:       50,
+		Duration:    5 * time.Millisecond,
+	})
+	metrics.ObserveRender(render.RenderEvent{
+		ContentType: render.JSON,
+		Template:    `a"b`,
+		Status:      500,
+		Code:        "unknown(2)",
+		Bytes:       150,
+		Duration:    50 * time.Millisecond,
+		Err:         assert.AnError,
+	})
+
+	w := httptest.NewRecorder()
+	metrics.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Equalf(t, render.MetricsContentType, w.Header().Get("Content-Type"), "content type")
+	text := w.Body.String()
+	for _, line := range []string{
+		`# TYPE render_responses_total counter`,
+		`render_responses_total{content_type="application/json",template="",status="200",code="success(0)"} 1`,
+		`render_responses_total{content_type="application/json",template="a\"b",status="500",code="unknown(2)"} 1`,
+		`render_errors_total{content_type="application/json"} 1`,
+		`# TYPE render_duration_seconds histogram`,
+		`render_duration_seconds_bucket{content_type="application/json",le="0.01"} 1`,
+		`render_duration_seconds_bucket{content_type="application/json",le="0.1"} 2`,
+		`render_duration_seconds_bucket{content_type="application/json",le="+Inf"} 2`,
+		`render_duration_seconds_count{content_type="application/json"} 2`,
+		`render_response_bytes_bucket{content_type="application/json",le="100"} 1`,
+		`render_response_bytes_bucket{content_type="application/json",le="+Inf"} 2`,
+		`render_response_bytes_sum{content_type="application/json"} 200`,
+	} {
+		assert.Truef(t, strings.Contains(text, line+"\n"), "missing %s in:\n%s", line, text)
+	}
+
+	metrics.Reset()
+	assert.Falsef(t, strings.Contains(string(metrics.Text()), "render_responses_total{"), "reset")
+
+	rd := render.New(render.WithObserver("metrics", metrics))
+	rd.OK(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil), render.JSON, "data")
+	assert.Truef(t, strings.Contains(string(metrics.Text()), `render_responses_total{content_type="application/json",template="",status="200",code="success(0)"} 1`), "observed by renderer")
+}
@@ -0,0 +1,134 @@
+package render
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Iterator defines the pull-style records producer, returns `more=false` when exhausted,
+// a non-nil error stops the stream
+type Iterator func() (record any, more bool, err error)
+
+// ndjsonRender implement `Render` for NDJSON(JSON Lines) streaming, data can be:
+//
+// - a channel of records, a record of `error` type stops the stream
+// - an `Iterator`, or `func() (any, bool, error)`
+// - an `io.Reader` of JSON values
+// - any other value, rendered as a single line
+//
+// each record is encoded as one line, the writer is flushed periodically(see `FlushEvery` and `FlushInterval`),
+// the stream stops when the context(see `StreamContext`) is done, if the producer fails mid-stream,
+// a final error line is written with the body of `NewResponse(nil, E(err))`
+type ndjsonRender struct{}
+
+// Streaming implement `Streamer`
+func (r ndjsonRender) Streaming() bool {
+	return true
+}
+
+// Render implement `Render`
+func (r ndjsonRender) Render(w http.ResponseWriter, data any, opts ...Option) error {
+	options := newStreamOptions(opts...)
+	header := w.Header()
+	if val := header[ContentTypeHeader]; len(val) == 0 {
+		header[ContentTypeHeader] = NDJSON.Header()
+	}
+	// NOTE: no explicit `WriteHeader`, the status may be written already(e.g. by `ResponseInterface`),
+	// otherwise the first write or flush sends 200
+	f := newFlusher(w, options)
+	defer f.Stop()
+	s := &ndjsonStream{
+		ctx:     options.Context,
+		enc:     json.NewEncoder(f),
+		flusher: f,
+	}
+	err := s.stream(data)
+	if err != nil && err != options.Context.Err() {
+		// NOTE: client is gone if context done, no need to write the error line
+		if eerr := s.enc.Encode(options.Renderer.NewResponse(nil, E(err)).Body()); eerr != nil {
+			err = fmt.Errorf("%w; write error line failed: %v", err, eerr)
+		}
+	}
+	s.flusher.Flush()
+	return err
+}
+
+type ndjsonStream struct {
+	ctx     context.Context
+	enc     *json.Encoder
+	flusher *flusher
+}
+
+func (s *ndjsonStream) stream(data any) error {
+	switch data := data.(type) {
+	case Iterator:
+		return s.iterate(data)
+	case func() (any, bool, error):
+		return s.iterate(data)
+	case io.Reader:
+		return s.read(data)
+	}
+	if ch, ok := recvChan(data); ok {
+		return receive(s.ctx, ch, func(record any) error {
+			if err, ok := record.(error); ok {
+				return err
+			}
+			return s.write(record)
+		})
+	}
+	return s.write(data)
+}
+
+func (s *ndjsonStream) write(record any) error {
+	if err := s.enc.Encode(record); err != nil {
+		return err
+	}
+	s.flusher.Tick()
+	return nil
+}
+
+func (s *ndjsonStream) iterate(next Iterator) error {
+	for {
+		if err := s.ctx.Err(); err != nil {
+			return err
+		}
+		record, more, err := next()
+		if err != nil {
+			return err
+		}
+		if !more {
+			return nil
+		}
+		if err := s.write(record); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *ndjsonStream) read(r io.Reader) error {
+	dec := json.NewDecoder(r)
+	for {
+		if err := s.ctx.Err(); err != nil {
+			return err
+		}
+		var record json.RawMessage
+		err := dec.Decode(&record)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := s.write(record); err != nil {
+			return err
+		}
+	}
+}
+
+var (
+	_ Render   = ndjsonRender{}
+	_ Streamer = ndjsonRender{}
+)
@@ -0,0 +1,97 @@
+package render_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ccmonky/errors"
+	"github.com/ccmonky/render"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNDJSON(t *testing.T) {
+	ch := make(chan int, 3)
+	ch <- 1
+	ch <- 2
+	ch <- 3
+	close(ch)
+	w := httptest.NewRecorder()
+	err := render.NDJSON.Render(w, ch, render.FlushEvery(2))
+	assert.Nilf(t, err, "channel err")
+	assert.Equalf(t, 200, w.Code, "status")
+	assert.Equalf(t, string(render.NDJSON), w.Header().Get("Content-Type"), "content-type")
+	assert.Equalf(t, "", w.Header().Get("Content-Length"), "not buffered")
+	assert.Truef(t, w.Flushed, "flushed")
+	assert.Equalf(t, "1\n2\n3\n", w.Body.String(), "body")
+
+	i := 0
+	iter := render.Iterator(func() (any, bool, error) {
+		i++
+		if i == 3 {
+			return nil, false, errors.NotFound
+		}
+		return map[string]int{"i": i}, true, nil
+	})
+	w = httptest.NewRecorder()
+	err = render.NDJSON.Render(w, iter)
+	assert.NotNilf(t, err, "iterator err")
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	assert.Equalf(t, 3, len(lines), "lines")
+	assert.Equalf(t, `{"i":1}`, lines[0], "line 1")
+	assert.Containsf(t, lines[2], `"code":"not_found(5)"`, "error line")
+
+	w = httptest.NewRecorder()
+	err = render.NDJSON.Render(w, strings.NewReader(`{"a": 1} {"b": 2}
+[3]`))
+	assert.Nilf(t, err, "reader err")
+	assert.Equalf(t, "{\"a\":1}\n{\"b\":2}\n[3]\n", w.Body.String(), "body")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	w = httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/", nil).WithContext(ctx)
+	err = render.NDJSON.OK(w, r, make(chan int))
+	assert.Equalf(t, context.Canceled, err, "canceled")
+	assert.Equalf(t, "", w.Body.String(), "no error line after cancel")
+
+	w = httptest.NewRecorder()
+	err = render.NDJSON.Render(w, make(chan int), render.StreamRequest(r))
+	assert.Equalf(t, context.Canceled, err, "canceled by deprecated StreamRequest")
+
+	hw := &writeHeaderRecorder{ResponseRecorder: httptest.NewRecorder()}
+	err = render.NDJSON.Render(hw, render.NewResponse(nil, render.E(errors.NotFound)))
+	assert.Nilf(t, err, "response err")
+	assert.Equalf(t, 404, hw.Code, "response status")
+	assert.Equalf(t, 1, hw.calls, "status written once")
+}
+
+type writeHeaderRecorder struct {
+	*httptest.ResponseRecorder
+	calls int
+}
+
+func (wr *writeHeaderRecorder) WriteHeader(status int) {
+	wr.calls++
+	wr.ResponseRecorder.WriteHeader(status)
+}
+
+func TestNDJSONFlushInterval(t *testing.T) {
+	ch := make(chan int)
+	w := &flushRecorder{ResponseRecorder: httptest.NewRecorder(), flushed: make(chan string, 4)}
+	done := make(chan error)
+	go func() {
+		done <- render.NDJSON.Render(w, ch, render.FlushEvery(100), render.FlushInterval(10*time.Millisecond))
+	}()
+	ch <- 1
+	select {
+	case body := <-w.flushed:
+		assert.Equalf(t, "1\n", body, "flushed by interval")
+	case <-time.After(time.Second):
+		t.Fatal("pending record not flushed by interval")
+	}
+	close(ch)
+	assert.Nilf(t, <-done, "closed")
+}
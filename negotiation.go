@@ -0,0 +1,132 @@
+package render
+
+import (
+	"context"
+	"net/http"
+)
+
+// NegotiaterName used to specify the name of negotiater registered in `Negotiaters` used by the route
+func NegotiaterName(name string) Option {
+	return func(o any) {
+		if options, ok := o.(*negotiationOptions); ok {
+			options.Negotiater = &name
+		}
+	}
+}
+
+// AllowedContentTypes used to restrict the candidate content types of the route, in preference order
+func AllowedContentTypes(cts ...ContentType) Option {
+	return func(o any) {
+		if options, ok := o.(*negotiationOptions); ok {
+			options.ContentTypes = cts
+		}
+	}
+}
+
+type negotiationOptions struct {
+	Negotiater   *string
+	ContentTypes []ContentType
+}
+
+// Negotiation returns the middleware which specifies the negotiation of the route, see `Renderer.Negotiation`
+func Negotiation(opts ...Option) func(http.Handler) http.Handler {
+	return defaultRenderer.Negotiation(opts...)
+}
+
+// ContextWithNegotiater returns the context carries the negotiater name used by `Negotiate`
+func ContextWithNegotiater(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, negotiaterKey{}, name)
+}
+
+// NegotiaterFromContext returns the negotiater name carried by context
+func NegotiaterFromContext(ctx context.Context) (string, bool) {
+	name, ok := ctx.Value(negotiaterKey{}).(string)
+	return name, ok
+}
+
+// ContextWithContentTypes returns the context carries the candidate content types used by `Negotiate`,
+// the order is the server preference order
+func ContextWithContentTypes(ctx context.Context, cts ...ContentType) context.Context {
+	return context.WithValue(ctx, contentTypesKey{}, cts)
+}
+
+// ContentTypesFromContext returns the candidate content types carried by context
+func ContentTypesFromContext(ctx context.Context) ([]ContentType, bool) {
+	cts, ok := ctx.Value(contentTypesKey{}).([]ContentType)
+	return cts, ok
+}
+
+// Negotiation returns the middleware which specifies the negotiater(see `NegotiaterName`) and restricts the candidate
+// content types(see `AllowedContentTypes`) of the route by request context, e.g. the admin route allows YAML
+// while the public route allows only JSON:
+//
+//	mux.Handle("/admin/", render.Negotiation(render.AllowedContentTypes(render.JSON, render.YAML))(admin))
+//	mux.Handle("/", render.Negotiation(render.AllowedContentTypes(render.JSON))(public))
+func (rd *Renderer) Negotiation(opts ...Option) func(http.Handler) http.Handler {
+	options := negotiationOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			if options.Negotiater != nil {
+				ctx = ContextWithNegotiater(ctx, *options.Negotiater)
+			}
+			if options.ContentTypes != nil {
+				ctx = ContextWithContentTypes(ctx, options.ContentTypes...)
+			}
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// candidates returns the candidate content types in preference order, restricted by context
+func (rd *Renderer) candidates(ctx context.Context) []ContentType {
+	allowed, ok := ContentTypesFromContext(ctx)
+	if !ok {
+		return rd.supportedContentTypes(ctx)
+	}
+	cts := make([]ContentType, 0, len(allowed))
+	for _, ct := range allowed {
+		if _, err := rd.getRender(ctx, ct); err == nil {
+			cts = append(cts, ct)
+		}
+	}
+	return cts
+}
+
+// fallback returns the default content type if it's a candidate, otherwise the first candidate
+func (rd *Renderer) fallback(ctx context.Context) ContentType {
+	allowed, ok := ContentTypesFromContext(ctx)
+	if !ok || len(allowed) == 0 {
+		return rd.defaultContentType
+	}
+	cts := rd.candidates(ctx)
+	for _, ct := range cts {
+		if ct == rd.defaultContentType {
+			return ct
+		}
+	}
+	if len(cts) > 0 {
+		return cts[0]
+	}
+	return rd.defaultContentType
+}
+
+// allowed tells if ct is a candidate
+func (rd *Renderer) allowed(ctx context.Context, ct ContentType) bool {
+	if _, ok := ContentTypesFromContext(ctx); !ok {
+		return true
+	}
+	for _, c := range rd.candidates(ctx) {
+		if c == ct {
+			return true
+		}
+	}
+	return false
+}
+
+type negotiaterKey struct{}
+
+type contentTypesKey struct{}
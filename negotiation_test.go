@@ -0,0 +1,98 @@
+package render_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ccmonky/render"
+	"github.com/stretchr/testify/assert"
+)
+
+type fixedNegotiater string
+
+func (n fixedNegotiater) Negotiate(acceptHeader string, ctypes ...string) (string, error) {
+	return string(n), nil
+}
+
+func TestNegotiation(t *testing.T) {
+	rd := render.New(render.WithRender(render.Text, render.RenderFunc(func(w http.ResponseWriter, data any, opts ...render.Option) error {
+		return nil
+	})))
+	rd.Negotiaters.Register(context.Background(), "fixed", fixedNegotiater(render.NDJSON))
+
+	var (
+		ct  render.ContentType
+		err error
+	)
+	handler := func(opts ...render.Option) http.Handler {
+		return rd.Negotiation(opts...)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ct, err = rd.NegotiateE(r)
+		}))
+	}
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Accept", "text/plain")
+	handler().ServeHTTP(httptest.NewRecorder(), r)
+	assert.Equalf(t, render.Text, ct, "no restriction")
+
+	public := handler(render.AllowedContentTypes(render.JSON))
+	public.ServeHTTP(httptest.NewRecorder(), r)
+	assert.Equalf(t, render.JSON, ct, "public lenient")
+	assert.Nilf(t, err, "public lenient err")
+
+	admin := handler(render.AllowedContentTypes(render.Text, render.JSON))
+	admin.ServeHTTP(httptest.NewRecorder(), r)
+	assert.Equalf(t, render.Text, ct, "admin")
+	r.Header.Set("Accept", "*/*")
+	admin.ServeHTTP(httptest.NewRecorder(), r)
+	assert.Equalf(t, render.Text, ct, "admin preference order")
+
+	r.Header.Set("Accept", "image/png")
+	handler(render.AllowedContentTypes(render.Text)).ServeHTTP(httptest.NewRecorder(), r)
+	assert.Equalf(t, render.Text, ct, "fallback to first allowed")
+
+	handler(render.NegotiaterName("fixed")).ServeHTTP(httptest.NewRecorder(), r)
+	assert.Equalf(t, render.NDJSON, ct, "named negotiater")
+
+	handler(render.NegotiaterName("missing")).ServeHTTP(httptest.NewRecorder(), r)
+	assert.NotNilf(t, err, "missing negotiater")
+
+	rd.SetNegotiatePolicy(render.Strict)
+	w := httptest.NewRecorder()
+	rd.Negotiation(render.AllowedContentTypes(render.JSON))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rd.NegotiateOrReject(w, r)
+	})).ServeHTTP(w, r)
+	assert.Equalf(t, 406, w.Code, "reject status")
+	var body struct {
+		Data struct {
+			Supported []string `json:"supported"`
+		} `json:"data"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &body)
+	assert.Equalf(t, []string{string(render.JSON)}, body.Data.Supported, "reject supported")
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("GET", "/items.text", nil)
+	rd.Format()(public).ServeHTTP(w, r)
+	assert.Equalf(t, render.JSON, ct, "format not allowed")
+	assert.NotNilf(t, err, "format not allowed err")
+}
+
+func TestNegotiateStreaming(t *testing.T) {
+	rd := render.New()
+	r := httptest.NewRequest("GET", "/", nil)
+	for _, accept := range []string{"text/*", "text/event-stream", "*/*;q=0.5", "application/x-ndjson"} {
+		r.Header.Set("Accept", accept)
+		assert.Equalf(t, render.JSON, rd.Negotiate(r), "streaming not negotiated for %s", accept)
+	}
+
+	var ct render.ContentType
+	r.Header.Set("Accept", "text/*")
+	rd.Negotiation(render.AllowedContentTypes(render.JSON, render.EventStream))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ct = rd.Negotiate(r)
+	})).ServeHTTP(httptest.NewRecorder(), r)
+	assert.Equalf(t, render.EventStream, ct, "streaming allowed by route")
+}
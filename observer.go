@@ -0,0 +1,157 @@
+package render
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/ccmonky/inithook"
+)
+
+// RenderEvent describes a render done by `ContentType.Render`(or `Renderer.Render`)
+type RenderEvent struct {
+	// ContentType the content type rendered with
+	ContentType ContentType
+
+	// Template the template of `Response`, empty if data is not a `Response`(or it's variant)
+	Template string
+
+	// Status the status written, 0 if nothing written
+	Status int
+
+	// Code the error code of `Response`, empty if data is not a `Response`(or it's variant)
+	Code string
+
+	// Bytes the number of body bytes written
+	Bytes int
+
+	// Duration the time spent on encoding and writing
+	Duration time.Duration
+
+	// Err the error returned by render, e.g. the encode error
+	Err error
+
+	// Request the request specified by `ForRequest`, nil if not specified
+	Request *http.Request
+
+	// Response the `Response` rendered, nil if data is not a `Response`(or it's variant),
+	// if encoding failed, it's the 500 `Response` rendered instead of data, and `Template`, `Code` are of it
+	Response *Response
+}
+
+// Observer observes the renders, it's invoked synchronously after each render, so it should be fast
+type Observer interface {
+	ObserveRender(event RenderEvent)
+}
+
+// ObserverFunc function implements `Observer`
+type ObserverFunc func(event RenderEvent)
+
+// ObserveRender implement `Observer`
+func (f ObserverFunc) ObserveRender(event RenderEvent) {
+	f(event)
+}
+
+// Observers used to store the observers of the default `Renderer`, key is the observer name, e.g.
+//
+//	metrics := render.NewMetrics()
+//	render.Observers.Register(ctx, "metrics", metrics)
+//	mux.Handle("/metrics", metrics)
+//
+// the observers are invoked in no particular order.
+var Observers = inithook.NewMap[string, Observer]()
+
+// Render renders data with the render of content type and notifies the `Observers`, see `ContentType.Render`
+func (rd *Renderer) Render(w http.ResponseWriter, ct ContentType, data any, opts ...Option) error {
+	observers := rd.Observers.Values(context.Background())
+	if len(observers) == 0 {
+		return rd.render(w, ct, data, opts...)
+	}
+	ow := &observedWriter{ResponseWriter: w}
+	start := time.Now()
+	err := rd.render(ow, ct, data, opts...)
+	event := RenderEvent{
+		ContentType: ct,
+		Status:      ow.status,
+		Bytes:       ow.bytes,
+		Duration:    time.Since(start),
+		Err:         err,
+	}
+	options := &observeOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	event.Request = options.Request
+	rendered := data
+	if ow.fellBack {
+		rendered = ow.fallback
+	}
+	if carrier, ok := rendered.(responseCarrier); ok {
+		if rp := carrier.response(); rp != nil {
+			event.Response = rp
+			event.Template = rp.Template
+			if rp.MetaError != nil {
+				event.Code = rp.MetaError.Code()
+			}
+		}
+	}
+	for _, observer := range observers {
+		observer.ObserveRender(event)
+	}
+	return err
+}
+
+// responseCarrier implemented by `*Response` and it's variants embedding it
+type responseCarrier interface {
+	response() *Response
+}
+
+func (rp *Response) response() *Response {
+	return rp
+}
+
+type observeOptions struct {
+	Request *http.Request
+}
+
+func (options *observeOptions) setRequest(r *http.Request) {
+	options.Request = r
+}
+
+// observedWriter records the status, the number of body bytes written and the fallback `Response`
+// rendered by `Renderer.renderError`(nil if fallback to plain text)
+type observedWriter struct {
+	http.ResponseWriter
+	status   int
+	bytes    int
+	fellBack bool
+	fallback ResponseInterface
+}
+
+func (ow *observedWriter) WriteHeader(status int) {
+	if ow.status == 0 && status >= 200 {
+		ow.status = status
+	}
+	ow.ResponseWriter.WriteHeader(status)
+}
+
+func (ow *observedWriter) Write(data []byte) (int, error) {
+	if ow.status == 0 {
+		ow.status = http.StatusOK
+	}
+	n, err := ow.ResponseWriter.Write(data)
+	ow.bytes += n
+	return n, err
+}
+
+// Flush implement `http.Flusher`, used by streaming renders
+func (ow *observedWriter) Flush() {
+	if f, ok := ow.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap used by `http.ResponseController`
+func (ow *observedWriter) Unwrap() http.ResponseWriter {
+	return ow.ResponseWriter
+}
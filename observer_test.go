@@ -0,0 +1,48 @@
+package render_test
+
+import (
+	"math"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ccmonky/errors"
+	"github.com/ccmonky/render"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObserver(t *testing.T) {
+	var events []render.RenderEvent
+	rd := render.New(render.WithObserver("test", render.ObserverFunc(func(event render.RenderEvent) {
+		events = append(events, event)
+	})))
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/users/1", nil)
+	rd.Err(w, r, render.ProblemJSON, errors.NotFound)
+	assert.Equalf(t, 1, len(events), "observed")
+	event := events[0]
+	assert.Equalf(t, render.ProblemJSON, event.ContentType, "content type")
+	assert.Equalf(t, render.ProblemTemplate, event.Template, "template")
+	assert.Equalf(t, 404, event.Status, "status")
+	assert.Equalf(t, "not_found(5)", event.Code, "code")
+	assert.Equalf(t, w.Body.Len(), event.Bytes, "bytes")
+	assert.Equalf(t, r, event.Request, "request")
+	assert.Nilf(t, event.Err, "no error")
+
+	w = httptest.NewRecorder()
+	err := rd.Render(w, render.JSON, math.NaN())
+	assert.NotNilf(t, err, "encode error")
+	event = events[1]
+	assert.Equalf(t, err, event.Err, "encode error observed")
+	assert.Equalf(t, 500, event.Status, "fallback status")
+	assert.Equalf(t, "unknown(2)", event.Code, "fallback code")
+	assert.Equalf(t, "", event.Template, "fallback template")
+
+	w = httptest.NewRecorder()
+	err = rd.Render(w, render.JSON, rd.NewResponse(math.NaN()))
+	assert.NotNilf(t, err, "encode error")
+	event = events[2]
+	assert.Equalf(t, 500, event.Status, "response fallback status")
+	assert.Equalf(t, "unknown(2)", event.Code, "response fallback code")
+	assert.Nilf(t, event.Response.Data, "fallback response")
+}
@@ -0,0 +1,375 @@
+// Package openapi generates the OpenAPI 3 components of the response envelopes from the templates,
+// so the spec does not drift from what `Response.Body` and the `Transformers` actually produce, e.g.
+//
+//	g := openapi.NewGenerator(nil)
+//	err := g.Envelope("UserResponse", User{}, "")
+//	err = errors.WithError(err, g.Envelope("UserProblem", User{}, render.ProblemTemplate))
+//	fragment, err := g.Components().YAML()
+//
+// the fragment is a `components` object which can be merged into the spec.
+package openapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ccmonky/errors"
+	"github.com/ccmonky/render"
+	"gopkg.in/yaml.v2"
+)
+
+// ErrorCodeSchema the schema name of the error code enum
+const ErrorCodeSchema = "ErrorCode"
+
+// Components the OpenAPI components object
+type Components struct {
+	Schemas   map[string]*Schema   `json:"schemas,omitempty" yaml:"schemas,omitempty"`
+	Headers   map[string]*Header   `json:"headers,omitempty" yaml:"headers,omitempty"`
+	Responses map[string]*Response `json:"responses,omitempty" yaml:"responses,omitempty"`
+}
+
+// JSON returns the json fragment of `{"components": c}`
+func (c *Components) JSON() ([]byte, error) {
+	return json.MarshalIndent(map[string]any{"components": c}, "", "  ")
+}
+
+// YAML returns the yaml fragment of `components: c`
+func (c *Components) YAML() ([]byte, error) {
+	return yaml.Marshal(map[string]any{"components": c})
+}
+
+// Schema the OpenAPI schema object(the subset used by envelopes)
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty" yaml:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty" yaml:"type,omitempty"`
+	Format               string             `json:"format,omitempty" yaml:"format,omitempty"`
+	Description          string             `json:"description,omitempty" yaml:"description,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty" yaml:"nullable,omitempty"`
+	Enum                 []any              `json:"enum,omitempty" yaml:"enum,omitempty"`
+	Items                *Schema            `json:"items,omitempty" yaml:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty" yaml:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty" yaml:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty" yaml:"additionalProperties,omitempty"`
+}
+
+// Header the OpenAPI header object
+type Header struct {
+	Ref         string  `json:"$ref,omitempty" yaml:"$ref,omitempty"`
+	Description string  `json:"description,omitempty" yaml:"description,omitempty"`
+	Schema      *Schema `json:"schema,omitempty" yaml:"schema,omitempty"`
+}
+
+// Response the OpenAPI response object
+type Response struct {
+	Description string                `json:"description" yaml:"description"`
+	Headers     map[string]*Header    `json:"headers,omitempty" yaml:"headers,omitempty"`
+	Content     map[string]*MediaType `json:"content,omitempty" yaml:"content,omitempty"`
+}
+
+// MediaType the OpenAPI media type object
+type MediaType struct {
+	Schema *Schema `json:"schema,omitempty" yaml:"schema,omitempty"`
+}
+
+// Generator generates the components of envelopes rendered by the renderer
+type Generator struct {
+	renderer    *render.Renderer
+	contentType render.ContentType
+	components  *Components
+}
+
+// NewGenerator creates a new `Generator` for the renderer, nil means `render.Default()`
+func NewGenerator(rd *render.Renderer) *Generator {
+	if rd == nil {
+		rd = render.Default()
+	}
+	return &Generator{
+		renderer:    rd,
+		contentType: rd.DefaultContentType(),
+		components: &Components{
+			Schemas:   make(map[string]*Schema),
+			Headers:   make(map[string]*Header),
+			Responses: make(map[string]*Response),
+		},
+	}
+}
+
+// Components returns the generated components
+func (g *Generator) Components() *Components {
+	return g.components
+}
+
+// Envelope generates the schema named name for the envelope of data type(a sample value, e.g. `User{}`)
+// rendered with template, the response headers, and one response named `<name><status>` for each status
+// of `render.MetaErrors`, register the application's errors into it to document them
+func (g *Generator) Envelope(name string, data any, tmpl string) error {
+	ctx := context.Background()
+	metas := render.MetaErrors.Values(ctx)
+	sort.Slice(metas, func(i, j int) bool {
+		return metas[i].Code() < metas[j].Code()
+	})
+	if len(metas) == 0 {
+		return fmt.Errorf("no meta error registered")
+	}
+	g.errorCodes(metas)
+
+	dataType := reflect.TypeOf(data)
+	sample := sampleOf(dataType)
+	// NOTE: the envelopes rendered by `OK` and `Err` always carry the request id
+	requestID := render.WithRequestID("request-id")
+	ok := g.renderer.NewResponse(sample, render.T(tmpl), requestID)
+	ve := render.NewValidationError().Add("field", "rule", "message")
+	failed := g.renderer.NewResponse(sample, render.T(tmpl), render.E(ve), requestID)
+	schema, err := g.bodySchema(ok.Body(), failed.Body(), dataType)
+	if err != nil {
+		return errors.WithMessagef(err, "generate schema of template %q failed", tmpl)
+	}
+	g.components.Schemas[name] = schema
+
+	headers := make(map[string]*Header)
+	for key := range ok.Header() {
+		key = http.CanonicalHeaderKey(key)
+		if _, ok := g.components.Headers[key]; !ok {
+			g.components.Headers[key] = g.headerOf(key)
+		}
+		headers[key] = &Header{Ref: "#/components/headers/" + key}
+	}
+
+	byStatus := make(map[int][]string)
+	for _, meta := range metas {
+		status := errors.StatusAttr.Get(meta)
+		byStatus[status] = append(byStatus[status], fmt.Sprintf("`%s`: %s", meta.Code(), meta.Message()))
+	}
+	mediaType := render.ParseMediaType(string(g.contentType)).String()
+	for ct, t := range g.renderer.DefaultTemplates.Map(ctx) {
+		if t == tmpl && tmpl != "" {
+			// NOTE: the template is the default of content type, e.g. `problem` of `ProblemJSON`
+			mediaType = render.ParseMediaType(string(ct)).String()
+		}
+	}
+	for status, codes := range byStatus {
+		g.components.Responses[fmt.Sprintf("%s%d", name, status)] = &Response{
+			Description: strings.Join(codes, "; "),
+			Headers:     headers,
+			Content: map[string]*MediaType{
+				mediaType: {Schema: &Schema{Ref: "#/components/schemas/" + name}},
+			},
+		}
+	}
+	return nil
+}
+
+// errorCodes generates the error code enum schema
+func (g *Generator) errorCodes(metas []errors.MetaError) {
+	enum := make([]any, 0, len(metas))
+	for _, meta := range metas {
+		enum = append(enum, meta.Code())
+	}
+	g.components.Schemas[ErrorCodeSchema] = &Schema{
+		Type:        "string",
+		Description: "error code",
+		Enum:        enum,
+	}
+}
+
+// bodySchema generates the schema of body, members present in both the success and failure bodies are required
+func (g *Generator) bodySchema(ok, failed any, dataType reflect.Type) (*Schema, error) {
+	okMap, isMap := ok.(map[string]any)
+	if !isMap {
+		return g.typeSchema(reflect.TypeOf(ok)), nil
+	}
+	failedMap, isMap := failed.(map[string]any)
+	if !isMap {
+		return nil, fmt.Errorf("body of failure is %T, not map", failed)
+	}
+	schema := &Schema{
+		Type:       "object",
+		Properties: make(map[string]*Schema),
+	}
+	for _, body := range []map[string]any{okMap, failedMap} {
+		for key, value := range body {
+			if _, ok := schema.Properties[key]; ok {
+				continue
+			}
+			schema.Properties[key] = g.memberSchema(key, value, dataType)
+		}
+	}
+	for key := range okMap {
+		if _, ok := failedMap[key]; ok {
+			schema.Required = append(schema.Required, key)
+		}
+	}
+	sort.Strings(schema.Required)
+	return schema, nil
+}
+
+func (g *Generator) memberSchema(key string, value any, dataType reflect.Type) *Schema {
+	switch key {
+	case "data":
+		return g.typeSchema(dataType)
+	case "code":
+		return &Schema{Ref: "#/components/schemas/" + ErrorCodeSchema}
+	case "status":
+		return &Schema{Type: "integer", Enum: g.statuses()}
+	}
+	if value == nil {
+		return &Schema{}
+	}
+	return g.typeSchema(reflect.TypeOf(value))
+}
+
+func (g *Generator) statuses() []any {
+	seen := make(map[int]bool)
+	var statuses []int
+	for _, meta := range render.MetaErrors.Values(context.Background()) {
+		if status := errors.StatusAttr.Get(meta); !seen[status] {
+			seen[status] = true
+			statuses = append(statuses, status)
+		}
+	}
+	sort.Ints(statuses)
+	enum := make([]any, 0, len(statuses))
+	for _, status := range statuses {
+		enum = append(enum, status)
+	}
+	return enum
+}
+
+// typeSchema generates the schema of type, named structs are generated as components and referenced
+func (g *Generator) typeSchema(t reflect.Type) *Schema {
+	if t == nil {
+		return &Schema{}
+	}
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case rawMessageType:
+		return &Schema{}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Pointer:
+		schema := g.typeSchema(t.Elem())
+		if schema.Ref != "" {
+			return schema
+		}
+		schema.Nullable = true
+		return schema
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.typeSchema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.typeSchema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		name := schemaName(t)
+		if _, ok := g.components.Schemas[name]; !ok {
+			g.components.Schemas[name] = &Schema{} // NOTE: placeholder for recursive types
+			g.components.Schemas[name] = g.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + name}
+	}
+	return &Schema{}
+}
+
+// structSchema generates the object schema of struct according to the json tags
+func (g *Generator) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{
+		Type:       "object",
+		Properties: make(map[string]*Schema),
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" || (!field.IsExported() && !field.Anonymous) {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		ft := field.Type
+		if field.Anonymous && name == "" {
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				embedded := g.structSchema(ft)
+				for k, v := range embedded.Properties {
+					schema.Properties[k] = v
+				}
+				schema.Required = append(schema.Required, embedded.Required...)
+				continue
+			}
+		}
+		if name == "" {
+			name = field.Name
+		}
+		schema.Properties[name] = g.typeSchema(ft)
+		if !strings.Contains(opts, "omitempty") && ft.Kind() != reflect.Pointer {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	sort.Strings(schema.Required)
+	return schema
+}
+
+func schemaName(t reflect.Type) string {
+	name := t.Name()
+	if i := strings.IndexByte(name, '['); i >= 0 {
+		// NOTE: generic type, e.g. TypedBody[main.User]
+		name = name[:i] + "_" + strings.NewReplacer(".", "_", "/", "_", "*", "", "]", "", ",", "_", " ", "").Replace(name[i+1:])
+	}
+	return name
+}
+
+// headerOf returns the header of canonical key, the meta headers are named by `Renderer.HeaderName`
+func (g *Generator) headerOf(key string) *Header {
+	switch key {
+	case http.CanonicalHeaderKey(g.renderer.HeaderName(render.MetaCode)):
+		return &Header{Description: "error code", Schema: &Schema{Ref: "#/components/schemas/" + ErrorCodeSchema}}
+	case http.CanonicalHeaderKey(g.renderer.HeaderName(render.MetaMessage)):
+		return &Header{Description: "error message", Schema: &Schema{Type: "string"}}
+	case http.CanonicalHeaderKey(g.renderer.HeaderName(render.MetaDetail)):
+		return &Header{Description: "error detail", Schema: &Schema{Type: "string"}}
+	case http.CanonicalHeaderKey(g.renderer.HeaderName(render.MetaRequestID)):
+		return &Header{Description: "request id", Schema: &Schema{Type: "string"}}
+	case render.TemplateHeader:
+		return &Header{Description: "response template", Schema: &Schema{Type: "string"}}
+	}
+	return &Header{Schema: &Schema{Type: "string"}}
+}
+
+// sampleOf returns a non-nil sample value of type, so the data member is not omitted
+func sampleOf(t reflect.Type) any {
+	if t == nil {
+		return nil
+	}
+	if t.Kind() == reflect.Pointer {
+		return reflect.New(t.Elem()).Interface()
+	}
+	return reflect.New(t).Elem().Interface()
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
@@ -0,0 +1,115 @@
+package openapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ccmonky/render"
+	"github.com/ccmonky/render/openapi"
+	"github.com/stretchr/testify/assert"
+)
+
+type Address struct {
+	City string `json:"city"`
+}
+
+type User struct {
+	Name      string            `json:"name"`
+	Age       int               `json:"age,omitempty"`
+	Tags      []string          `json:"tags"`
+	Address   *Address          `json:"address"`
+	Extra     map[string]string `json:"extra,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+	secret    string
+}
+
+func TestEnvelope(t *testing.T) {
+	g := openapi.NewGenerator(nil)
+	err := g.Envelope("UserResponse", User{}, "")
+	assert.Nilf(t, err, "default envelope")
+	c := g.Components()
+
+	schema := c.Schemas["UserResponse"]
+	assert.Equalf(t, "object", schema.Type, "type")
+	assert.Equalf(t, []string{"app", "code", "data", "detail", "message", "request_id", "timestamp", "version"}, schema.Required, "required")
+	assert.Equalf(t, "string", schema.Properties["request_id"].Type, "request_id")
+	assert.Equalf(t, "#/components/schemas/User", schema.Properties["data"].Ref, "data")
+	assert.Equalf(t, "#/components/schemas/ErrorCode", schema.Properties["code"].Ref, "code")
+	assert.Equalf(t, "array", schema.Properties["fields"].Type, "fields")
+	assert.Equalf(t, "integer", schema.Properties["timestamp"].Type, "timestamp")
+
+	user := c.Schemas["User"]
+	assert.Equalf(t, []string{"created_at", "name", "tags"}, user.Required, "user required")
+	assert.Equalf(t, "#/components/schemas/Address", user.Properties["address"].Ref, "address")
+	assert.Equalf(t, "date-time", user.Properties["created_at"].Format, "created_at")
+	assert.Equalf(t, "string", user.Properties["extra"].AdditionalProperties.Type, "extra")
+	_, ok := user.Properties["secret"]
+	assert.Falsef(t, ok, "unexported")
+
+	assert.Containsf(t, c.Schemas["ErrorCode"].Enum, string(render.ValidationFailed.Code()), "code enum")
+	assert.Equalf(t, "#/components/schemas/ErrorCode", c.Headers["X-Code"].Schema.Ref, "x-code header")
+	assert.Containsf(t, c.Headers, "X-Render-Template", "template header")
+	rp := c.Responses["UserResponse422"]
+	assert.Containsf(t, rp.Description, "validation_failed", "422 description")
+	assert.Equalf(t, "#/components/schemas/UserResponse", rp.Content["application/json"].Schema.Ref, "422 content")
+	assert.Equalf(t, "#/components/headers/X-Message", rp.Headers["X-Message"].Ref, "422 headers")
+	assert.Equalf(t, "#/components/headers/X-Request-Id", rp.Headers["X-Request-Id"].Ref, "request id header")
+	assert.Equalf(t, "request id", c.Headers["X-Request-Id"].Description, "request id header description")
+}
+
+func TestEnvelopeHeaderNames(t *testing.T) {
+	rd := render.New(render.WithHeaderPrefix("Y-"), render.WithHeaderName(render.MetaCode, "Grpc-Status"))
+	g := openapi.NewGenerator(rd)
+	err := g.Envelope("UserResponse", User{}, "")
+	assert.Nilf(t, err, "envelope")
+	c := g.Components()
+	assert.Equalf(t, "#/components/schemas/ErrorCode", c.Headers["Grpc-Status"].Schema.Ref, "renamed code header")
+	assert.Equalf(t, "error message", c.Headers["Y-Message"].Description, "prefixed message header")
+	assert.NotContainsf(t, c.Headers, "X-Code", "default code header")
+}
+
+func TestEnvelopeProblem(t *testing.T) {
+	g := openapi.NewGenerator(nil)
+	err := g.Envelope("UserProblem", User{}, render.ProblemTemplate)
+	assert.Nilf(t, err, "problem envelope")
+	c := g.Components()
+	schema := c.Schemas["UserProblem"]
+	assert.Containsf(t, schema.Properties["status"].Enum, 404, "status enum")
+	assert.Equalf(t, "array", schema.Properties["errors"].Type, "errors")
+	assert.NotContainsf(t, schema.Required, "errors", "errors optional")
+	assert.Containsf(t, schema.Required, "request_id", "problem request_id")
+	assert.Containsf(t, c.Responses["UserProblem404"].Content, "application/problem+json", "problem content type")
+}
+
+func TestEnvelopeTyped(t *testing.T) {
+	err := render.Transformers.Register(context.Background(), "typed_user", render.TypedTransformer[User]())
+	assert.Nilf(t, err, "register")
+	g := openapi.NewGenerator(nil)
+	err = g.Envelope("TypedUser", User{}, "typed_user")
+	assert.Nilf(t, err, "typed envelope")
+	c := g.Components()
+	assert.Equalf(t, "#/components/schemas/TypedBody_github_com_ccmonky_render_openapi_test_User", c.Schemas["TypedUser"].Ref, "typed ref")
+	body := c.Schemas["TypedBody_github_com_ccmonky_render_openapi_test_User"]
+	assert.Equalf(t, "#/components/schemas/User", body.Properties["data"].Ref, "typed data")
+	_, ok := body.Properties["XMLName"]
+	assert.Falsef(t, ok, "xml name")
+}
+
+func TestFragment(t *testing.T) {
+	g := openapi.NewGenerator(nil)
+	err := g.Envelope("UserResponse", User{}, "")
+	assert.Nilf(t, err, "envelope")
+	data, err := g.Components().YAML()
+	assert.Nilf(t, err, "yaml")
+	assert.Truef(t, strings.HasPrefix(string(data), "components:\n"), "yaml root")
+	assert.Containsf(t, string(data), "$ref: '#/components/schemas/User'", "yaml ref")
+	data, err = g.Components().JSON()
+	assert.Nilf(t, err, "json")
+	var fragment map[string]map[string]any
+	err = json.Unmarshal(data, &fragment)
+	assert.Nilf(t, err, "json unmarshal")
+	assert.Containsf(t, fragment["components"], "schemas", "json schemas")
+}
@@ -0,0 +1,92 @@
+package render
+
+import (
+	"context"
+
+	"github.com/ccmonky/inithook"
+	"go.uber.org/atomic"
+)
+
+// ProblemTemplate the template name of `ProblemResponse`
+const ProblemTemplate = "problem"
+
+// ProblemInstanceKey the `Response` extension key used to specify the `instance` member, e.g.
+//
+//	render.NewResponse(nil, render.E(err), render.T(render.ProblemTemplate), render.KV(render.ProblemInstanceKey, r.URL.Path))
+const ProblemInstanceKey = "instance"
+
+var (
+	// ProblemTypes used to store the `type` URI of error code, if not found,
+	// the `type` is the base set by `SetProblemTypeBase` joined with the code, or `about:blank` if base is empty
+	ProblemTypes = inithook.NewMap[string, string]()
+
+	// ProblemExtensions used to store the keys of `Response` values(extension or `errors.Map`) exposed
+	// as extension members, the value is the member name, e.g.
+	//
+	//	render.ProblemExtensions.Register(ctx, "retry_after", "retryAfter")
+	ProblemExtensions = inithook.NewMap[string, string]()
+)
+
+// SetProblemTypeBase sets the base URI of problem `type`, e.g. `https://docs.example.com/errors/`
+func SetProblemTypeBase(base string) {
+	problemTypeBase.Store(base)
+}
+
+// ProblemResponse is the `Response` variant for problem details(RFC 9457),
+// use `ProblemJSON` to render it with `application/problem+json` content type
+type ProblemResponse struct {
+	*Response
+}
+
+// Body implement `ResponseInterface`, returns the problem details object, the field violations of
+// `ValidationError` are exposed as the `errors` member, and the request id as the `request_id` member
+func (pr ProblemResponse) Body() any {
+	code := pr.MetaError.Code()
+	body := map[string]any{
+		"type":   problemType(code),
+		"title":  pr.MetaError.Message(),
+		"status": pr.Status(),
+		"detail": pr.Detail(),
+		"code":   code,
+	}
+	if instance, ok := Get(pr.Response, ProblemInstanceKey); ok {
+		body["instance"] = instance
+	}
+	for key, member := range ProblemExtensions.Map(context.Background()) {
+		if value, ok := Get(pr.Response, key); ok {
+			body[member] = value
+		}
+	}
+	if len(pr.Fields) > 0 {
+		body["errors"] = pr.Fields
+	}
+	if pr.RequestID != "" {
+		body["request_id"] = pr.RequestID
+	}
+	if pr.Data != nil {
+		body["data"] = pr.Data
+	}
+	return body
+}
+
+func problemType(code string) string {
+	if typ, err := ProblemTypes.Get(context.Background(), code); err == nil {
+		return typ
+	}
+	if base := problemTypeBase.Load(); base != "" {
+		return base + code
+	}
+	return "about:blank"
+}
+
+func problemResponseTransformer(rp *Response) ResponseInterface {
+	return ProblemResponse{rp}
+}
+
+var (
+	problemTypeBase = atomic.NewString("")
+)
+
+var (
+	_ ResponseInterface = ProblemResponse{}
+)
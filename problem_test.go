@@ -0,0 +1,56 @@
+package render_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ccmonky/errors"
+	"github.com/ccmonky/render"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProblem(t *testing.T) {
+	ctx := context.Background()
+	render.SetProblemTypeBase("https://docs.example.com/errors/")
+	defer render.SetProblemTypeBase("")
+	render.ProblemTypes.Set(ctx, "already_exists(6)", "https://docs.example.com/conflict")
+	defer render.ProblemTypes.Delete(ctx, "already_exists(6)")
+	render.ProblemExtensions.Set(ctx, "status", "httpStatus")
+	defer render.ProblemExtensions.Delete(ctx, "status")
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/users/1", nil)
+	r.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	render.ProblemJSON.Err(w, r, errors.NotFound)
+	assert.Equalf(t, 404, w.Code, "status")
+	assert.Equalf(t, "application/problem+json", w.Header().Get("Content-Type"), "content-type")
+	assert.Equalf(t, "problem", w.Header().Get("X-Render-Template"), "X-Render-Template")
+	expect := `{
+		"type": "https://docs.example.com/errors/not_found(5)",
+		"title": "not found",
+		"status": 404,
+		"detail": "meta={source=errors;code=not_found(5)}:status={404}",
+		"code": "not_found(5)",
+		"httpStatus": 404,
+		"request_id": "4bf92f3577b34da6a3ce929d0e0e4736"
+	}`
+	assert.JSONEq(t, expect, w.Body.String(), "body")
+
+	w = httptest.NewRecorder()
+	render.ProblemJSON.Render(w, render.NewResponse(nil,
+		render.E(errors.WithError(errors.New("xxx"), errors.AlreadyExists)),
+		render.T(render.ProblemTemplate),
+		render.KV(render.ProblemInstanceKey, "/users/1"),
+	))
+	assert.Equalf(t, 409, w.Code, "status")
+	var body map[string]any
+	assert.Nilf(t, json.Unmarshal(w.Body.Bytes(), &body), "unmarshal")
+	assert.Equalf(t, "https://docs.example.com/conflict", body["type"], "type")
+	assert.Equalf(t, "/users/1", body["instance"], "instance")
+
+	render.SetProblemTypeBase("")
+	rp := render.NewResponse(nil, render.E(errors.NotFound), render.T(render.ProblemTemplate))
+	assert.Equalf(t, "about:blank", rp.Body().(map[string]any)["type"], "type")
+}
@@ -5,7 +5,7 @@ import (
 	"encoding/json"
 	"log"
 	"net/http"
-	"strings"
+	"time"
 
 	"github.com/ccmonky/errors"
 	"github.com/ccmonky/inithook"
@@ -26,6 +26,7 @@ const (
 // DefaultNegotiaterName default negotiater name
 const DefaultNegotiaterName = ""
 
+// The registries of the default `Renderer`, use `New` to create a `Renderer` with its own registries
 var (
 	// Renders the renders registry, it's aim to store third party renders,
 	// note, that's no need to store the `Response` render like JSON, HTML, ...
@@ -34,8 +35,12 @@ var (
 	// ContentTypes used to store the mapping of the name to `ContentType`
 	ContentTypes = inithook.NewMap[string, ContentType]()
 
-	// Negotiaters the negotiaters registry, now only the DefaultNegotiaterName used
+	// Negotiaters the negotiaters registry, the negotiater is selected by name from request context(see `ContextWithNegotiater`
+	// and `Negotiation`), default to the `DefaultNegotiaterName` one
 	Negotiaters = inithook.NewMap[string, Negotiater]()
+
+	// DefaultTemplates used to store the default template of `ContentType`, e.g. "problem" for `ProblemJSON`
+	DefaultTemplates = inithook.NewMap[ContentType, string]()
 )
 
 const (
@@ -73,6 +78,21 @@ const (
 
 	// XHTML content type render for xhtml
 	XHTML ContentType = "application/xhtml+xml; charset=utf-8"
+
+	// Form content type for url encoded form, used as request content type
+	Form ContentType = "application/x-www-form-urlencoded"
+
+	// MultipartForm content type for multipart form, used as request content type
+	MultipartForm ContentType = "multipart/form-data"
+
+	// ProblemJSON content type render for problem details(RFC 9457)
+	ProblemJSON ContentType = "application/problem+json"
+
+	// NDJSON content type render for newline delimited json streaming
+	NDJSON ContentType = "application/x-ndjson"
+
+	// EventStream content type render for server-sent events
+	EventStream ContentType = "text/event-stream"
 )
 
 var (
@@ -101,6 +121,21 @@ type Render interface {
 // Option used to support the `Render` with dynamic parameters, e.g., jsonp, html, ...
 type Option func(any)
 
+// ForRequest used to make renders aware of the request, e.g. streaming renders stop when the request context done,
+// `ContentType.OK` and `ContentType.Err` use it by default
+func ForRequest(r *http.Request) Option {
+	return func(o any) {
+		if options, ok := o.(requestAware); ok {
+			options.setRequest(r)
+		}
+	}
+}
+
+// requestAware implemented by options of request aware renders
+type requestAware interface {
+	setRequest(*http.Request)
+}
+
 // RenderFunc defines the function that implement Render
 type RenderFunc func(http.ResponseWriter, interface{}, ...Option) error
 
@@ -112,7 +147,7 @@ func (rf RenderFunc) Render(w http.ResponseWriter, data interface{}, opts ...Opt
 type ContentType string
 
 func (ct ContentType) Ready() bool {
-	return Renders.Has(context.Background(), ct)
+	return defaultRenderer.Ready(ct)
 }
 
 // Header returns header value slice of content type
@@ -120,12 +155,43 @@ func (ct ContentType) Header() []string {
 	return []string{string(ct)}
 }
 
-// Render implement `Render` interface, mainly used to extra suppport `ResponseInterface`
+// Render implement `Render` interface, mainly used to extra suppport `ResponseInterface`.
+// By default the body is encoded into a buffer before anything is written(encode-then-commit),
+// if encoding failed, the status, headers and body are replaced by a 500 `Response` rendered by the same render,
+// use `Unbuffered` option or implement `Streamer` to opt out.
+// Conditional GET is supported with the request specified by `ForRequest` except for streaming render,
+// see `ETag`, `GenerateETag` and `LastModified`.
+// The `Observers` are notified after each render, see `RenderEvent`.
 func (ct ContentType) Render(w http.ResponseWriter, rp interface{}, opts ...Option) error {
-	render, err := Renders.Get(context.TODO(), ct)
-	if err != nil {
-		return errors.WithMessagef(err, "get render failed for %v", ct)
+	return defaultRenderer.Render(w, ct, rp, opts...)
+}
+
+// renderOptions the options of `ContentType.Render` itself
+type renderOptions struct {
+	Unbuffered   bool
+	ETag         string
+	GenerateETag bool
+	WeakETag     bool
+	LastModified time.Time
+	Request      *http.Request
+}
+
+func (options *renderOptions) setRequest(r *http.Request) {
+	options.Request = r
+}
+
+func newRenderOptions(render Render, opts ...Option) *renderOptions {
+	options := &renderOptions{}
+	for _, opt := range opts {
+		opt(options)
 	}
+	if s, ok := render.(Streamer); ok && s.Streaming() {
+		options.Unbuffered = true
+	}
+	return options
+}
+
+func (ct ContentType) render(w http.ResponseWriter, render Render, rp interface{}, opts ...Option) error {
 	switch rp := rp.(type) {
 	case ResponseInterface:
 		header := w.Header()
@@ -133,6 +199,11 @@ func (ct ContentType) Render(w http.ResponseWriter, rp interface{}, opts ...Opti
 			header[ContentTypeHeader] = ct.Header()
 		}
 		for k, vs := range rp.Header() {
+			if isValidator(k) {
+				// NOTE: the validators of response replace the ones of options, only one is allowed
+				header[k] = vs
+				continue
+			}
 			for _, v := range vs {
 				header.Add(k, v)
 			}
@@ -144,22 +215,27 @@ func (ct ContentType) Render(w http.ResponseWriter, rp interface{}, opts ...Opti
 	}
 }
 
-// OK do render for success with data as result, and automatic select template with `*http.Request`
+// OK do render for success with data as result, and automatic select template with `*http.Request`,
+// note that data is not wrapped for streaming render(see `Streamer`), and the request context is used to stop the stream
 func (ct ContentType) OK(w http.ResponseWriter, r *http.Request, data interface{}, opts ...Option) error {
-	if _, ok := data.(ResponseInterface); !ok && r != nil {
-		rp := NewResponse(data, T(r.Header.Get(TemplateHeader)))
-		return ct.Render(w, rp, opts...)
-	}
-	return ct.Render(w, data, opts...)
+	return defaultRenderer.OK(w, r, ct, data, opts...)
 }
 
+// Err do render for failure with err as response meta, and automatic select template with `*http.Request`
 func (ct ContentType) Err(w http.ResponseWriter, r *http.Request, err error, opts ...Option) error {
-	return ct.Render(w, NewResponse(nil, E(err), T(r.Header.Get(TemplateHeader))), opts...)
+	return defaultRenderer.Err(w, r, ct, err, opts...)
+}
+
+// Template returns the template specified by request's `TemplateHeader`, if not specified, returns the template
+// of vendor media type registered in `VendorTypes`, or the default template of content type registered in `DefaultTemplates`
+func (ct ContentType) Template(r *http.Request) string {
+	return defaultRenderer.Template(r, ct)
 }
 
-// GetRenderByName returns the content type for name
+// GetRenderByName returns the content type for name, it panics if name unknown, use `Renderer.ContentType` or
+// `Format` middleware for the names from requests
 func GetRenderByName(name string) ContentType {
-	ct, err := ContentTypes.Get(context.Background(), strings.ToLower(name))
+	ct, err := defaultRenderer.ContentType(name)
 	if err != nil {
 		log.Panicf("get content type for name %s failed: %v", name, err)
 		return JSON
@@ -167,59 +243,64 @@ func GetRenderByName(name string) ContentType {
 	return ct
 }
 
-// Negotiate used to select the response content-type according to http request, default to `JSON`
-// The default behavior can be changed, e.g.
-//
-//     import "github.com/timewasted/go-accept-headers"
-//
-//     type AcceptNegotiater struct{}
-//
-//     func (n AcceptNegotiater) Negotiate(acceptHeader string, ctypes ...string) (ctype string, err error) {
-//     	   return accept.Parse(acceptHeader).Negotiate(ctypes...)
-//     }
+// Negotiate used to select the response content-type according to http request, default to `JSON`,
+// it never panics, if negotiation failed, the default render is returned, use `NegotiateE` or
+// `NegotiateOrReject` to handle the failure.
+func Negotiate(r *http.Request) ContentType {
+	return defaultRenderer.Negotiate(r)
+}
+
+// NegotiateE used to select the response content-type according to http request and returns the error if failed.
+// The content type resolved from format(see `Format`) is returned directly if present.
+// The candidates are the keys of `Renders` sorted by `SortContentTypes` followed by the vendor media types of
+// `VendorTypes`, or the content types allowed by the route(see `Negotiation`), the streaming renders(see `Streamer`)
+// are candidates only if allowed by the route. The negotiater named by the route
+// or registered as `DefaultNegotiaterName`(`AcceptNegotiater` as default) is used, which can be changed, e.g.
 //
-//     func init(){
-//	       _ = render.Negotiaters.Set(ctx, render.DefaultNegotiaterName, AcceptNegotiater{})
-//     }
+//	func init() {
+//		_ = render.Negotiaters.Set(ctx, render.DefaultNegotiaterName, MyNegotiater{})
+//	}
 //
-func Negotiate(r *http.Request) ContentType {
-	if r == nil {
-		return defaultRender
-	}
-	header := r.Header.Get(AcceptHeader)
-	if header == "" {
-		return defaultRender
-	}
-	var ctypes []string
-	for _, k := range Renders.Keys(r.Context()) {
-		ctypes = append(ctypes, string(k))
-	}
-	negotiaterName := DefaultNegotiaterName
-	negotiater, err := Negotiaters.Get(r.Context(), negotiaterName)
-	if err != nil {
-		log.Panicf("get negotiater %s failed: %v", negotiaterName, err)
-	}
-	ctype, err := negotiater.Negotiate(header, ctypes...)
-	if err != nil {
-		log.Panicf("negotiate failed: %v", err)
-	}
-	ct := ContentType(ctype)
-	_, err = Renders.Get(r.Context(), ct)
-	if err != nil {
-		log.Panicf("render not found for %v", ct)
-	}
-	return ct
+// If no content type is acceptable, with `Lenient` policy the default render and nil are returned,
+// with `Strict` policy the default render and a `NotAcceptable` error are returned, the default render is
+// the first allowed content type if the route does not allow the default one.
+// Other errors, e.g. negotiater not found, are always returned along with the default render.
+func NegotiateE(r *http.Request) (ContentType, error) {
+	return defaultRenderer.NegotiateE(r)
 }
 
-// Negotiater used to negotiate content type between client accepts and server supports
-type Negotiater interface {
-	Negotiate(acceptHeader string, ctypes ...string) (ctype string, err error)
+// NegotiateOrReject used to select the response content-type like `NegotiateE`, if failed,
+// render the error(406 for `NotAcceptable`) with the default render, the data of response
+// lists the supported content types, and returns false.
+func NegotiateOrReject(w http.ResponseWriter, r *http.Request) (ContentType, bool) {
+	return defaultRenderer.NegotiateOrReject(w, r)
 }
 
-type defaultNegotiater struct{}
+// NegotiatePolicy defines the behavior when no content type is acceptable
+type NegotiatePolicy int32
 
-func (n defaultNegotiater) Negotiate(acceptHeader string, ctypes ...string) (ctype string, err error) {
-	return string(JSON), nil
+const (
+	// Lenient falls back to the default render
+	Lenient NegotiatePolicy = iota
+
+	// Strict returns `NotAcceptable` error
+	Strict
+)
+
+// SetNegotiatePolicy sets the negotiate policy, default is `Lenient`
+func SetNegotiatePolicy(policy NegotiatePolicy) {
+	defaultRenderer.SetNegotiatePolicy(policy)
+}
+
+// GetNegotiatePolicy returns the negotiate policy
+func GetNegotiatePolicy() NegotiatePolicy {
+	return defaultRenderer.NegotiatePolicy()
+}
+
+// Negotiater used to negotiate content type between client accepts and server supports,
+// the order of ctypes is the server preference order
+type Negotiater interface {
+	Negotiate(acceptHeader string, ctypes ...string) (ctype string, err error)
 }
 
 // jsonRender implement `Render` for json format as default render
@@ -240,32 +321,11 @@ func (r jsonRender) Render(w http.ResponseWriter, data any, opts ...Option) (err
 }
 
 func init() {
-	ctx := context.Background()
-	err := Renders.Register(ctx, JSON, jsonRender{})
-	err = errors.WithError(err, Negotiaters.Register(ctx, DefaultNegotiaterName, defaultNegotiater{}))
-
-	err = errors.WithError(err, ContentTypes.Register(ctx, "", JSON))
-	err = errors.WithError(err, ContentTypes.Register(ctx, "json", JSON))
-	err = errors.WithError(err, ContentTypes.Register(ctx, "jsonascii", JSONASCII))
-	err = errors.WithError(err, ContentTypes.Register(ctx, "jsonp", JSONP))
-	err = errors.WithError(err, ContentTypes.Register(ctx, "html", HTML))
-	err = errors.WithError(err, ContentTypes.Register(ctx, "text", Text))
-	err = errors.WithError(err, ContentTypes.Register(ctx, "protobuf", PROTOBUF))
-	err = errors.WithError(err, ContentTypes.Register(ctx, "binary", Binary))
-	err = errors.WithError(err, ContentTypes.Register(ctx, "yaml", YAML))
-	err = errors.WithError(err, ContentTypes.Register(ctx, "toml", TOML))
-	err = errors.WithError(err, ContentTypes.Register(ctx, "xml", XML))
-	err = errors.WithError(err, ContentTypes.Register(ctx, "msgpack", MSGPACK))
-	err = errors.WithError(err, ContentTypes.Register(ctx, "xhtml", XHTML))
-	if err != nil {
+	if err := defaultRenderer.registerBuiltins(context.Background()); err != nil {
 		log.Panicln(errors.GetAllErrors(err))
 	}
 }
 
-var (
-	defaultRender = JSON
-)
-
 var (
 	_ Render = (*ContentType)(nil)
 	_ Render = (*RenderFunc)(nil)
@@ -13,7 +13,6 @@ import (
 	"github.com/ccmonky/inithook"
 	"github.com/ccmonky/render"
 	"github.com/stretchr/testify/assert"
-	"github.com/timewasted/go-accept-headers"
 )
 
 func init() {
@@ -74,13 +73,13 @@ func TestDefault(t *testing.T) {
 	w := newResponseWriter()
 	render.JSON.Render(w, nil)
 	assert.Equalf(t, 200, w.status, "status")
-	assert.Equalf(t, 1, len(w.header), "header")
+	assert.Equalf(t, 2, len(w.header), "header")
 	assert.Equalf(t, "null", w.body.String(), "body")
 
 	w = newResponseWriter()
 	render.JSON.Render(w, 101)
 	assert.Equalf(t, 200, w.status, "status")
-	assert.Equalf(t, 1, len(w.header), "header")
+	assert.Equalf(t, 2, len(w.header), "header")
 	assert.Equalf(t, "101", w.body.String(), "body")
 
 	w = newResponseWriter()
@@ -89,7 +88,7 @@ func TestDefault(t *testing.T) {
 		"string": "string",
 	})
 	assert.Equalf(t, 200, w.status, "status")
-	assert.Equalf(t, 1, len(w.header), "header")
+	assert.Equalf(t, 2, len(w.header), "header")
 	assert.JSONEq(t, `{"one":1,"string":"string"}`, w.body.String(), "body")
 
 	w = newResponseWriter()
@@ -99,7 +98,7 @@ func TestDefault(t *testing.T) {
 		"string": "string",
 	}, render.E(errors.NotFound), render.T("no_timestamp")))
 	assert.Equalf(t, 404, w.status, "status")
-	assert.Equalf(t, 7, len(w.header), "header")
+	assert.Equalf(t, 8, len(w.header), "header")
 	body := `{
 		"app": "myapp",
 		"code": "not_found(5)",
@@ -173,6 +172,7 @@ func TestOK(t *testing.T) {
 		t.Fatal(err)
 	}
 	req.Header.Set(render.TemplateHeader, "no_timestamp")
+	req.Header.Set(render.RequestIDHeader, "req-1")
 	client := &http.Client{}
 	res, err := client.Do(req)
 	if err != nil {
@@ -201,6 +201,7 @@ func TestOK(t *testing.T) {
 		},
 		"detail": "meta={source=errors;code=success(0)}:status={200}",
 		"message": "success",
+		"request_id": "req-1",
 		"version": "0.3.0"
 	}`
 	assert.JSONEq(t, expect, string(body), "body")
@@ -218,6 +219,7 @@ func TestErr(t *testing.T) {
 	}
 	req.Header.Set(render.TemplateHeader, "no_timestamp")
 	req.Header.Set(render.AcceptHeader, "application/json, text/html, application/xhtml+xml, application/xml;q=0.9, */*;q=0.8")
+	req.Header.Set(render.RequestIDHeader, "req-1")
 	client := &http.Client{}
 	res, err := client.Do(req)
 	if err != nil {
@@ -237,12 +239,14 @@ func TestErr(t *testing.T) {
 	assert.Equalf(t, "xxx:error={meta={source=errors;code=already_exists(6)}:status={409}}", res.Header.Get("X-Detail"), "x-detail")
 	assert.Equalf(t, "already exists", res.Header.Get("X-Message"), "x-message")
 	assert.Equalf(t, "no_timestamp", res.Header.Get("X-Render-Template"), "X-Render-Template")
+	assert.Equalf(t, "req-1", res.Header.Get("X-Request-Id"), "X-Request-Id")
 	expect := `{
 		"app": "myapp",
 		"code": "already_exists(6)",
 		"data": null,
 		"detail": "xxx:error={meta={source=errors;code=already_exists(6)}:status={409}}",
 		"message": "already exists",
+		"request_id": "req-1",
 		"version": "0.3.0"
 	}`
 	assert.JSONEq(t, expect, string(body), "body")
@@ -250,9 +254,7 @@ func TestErr(t *testing.T) {
 
 func TestNegotiate(t *testing.T) {
 	ctx := context.Background()
-	err := render.Negotiaters.Set(ctx, "", AcceptNegotiater{})
-	assert.Nilf(t, err, "set negotiater")
-	n, err := render.Negotiaters.Get(ctx, "")
+	n, err := render.Negotiaters.Get(ctx, render.DefaultNegotiaterName)
 	assert.Nilf(t, err, "get negotiater")
 
 	header := ""
@@ -285,11 +287,48 @@ func TestNegotiate(t *testing.T) {
 
 	ctype, err = n.Negotiate(header, string(render.JSON), string(render.XML), string(render.XHTML), string(render.HTML))
 	assert.Nilf(t, err, "negotiate err")
+	assert.Equalf(t, string(render.XHTML), ctype, "accept=%s", header)
+
+	ctype, err = n.Negotiate(header, string(render.JSON), string(render.HTML), string(render.XML), string(render.XHTML))
+	assert.Nilf(t, err, "negotiate err")
 	assert.Equalf(t, string(render.HTML), ctype, "accept=%s", header)
-}
 
-type AcceptNegotiater struct{}
+	header = "image/png"
+	_, err = n.Negotiate(header, string(render.JSON), string(render.HTML))
+	assert.NotNilf(t, err, "negotiate err")
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(render.AcceptHeader, "image/png")
+	assert.Equalf(t, render.JSON, render.Negotiate(req), "fallback to default")
+}
 
-func (n AcceptNegotiater) Negotiate(acceptHeader string, ctypes ...string) (ctype string, err error) {
-	return accept.Parse(acceptHeader).Negotiate(ctypes...)
+func TestNegotiateE(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(render.AcceptHeader, "image/png")
+
+	ct, err := render.NegotiateE(req)
+	assert.Nilf(t, err, "lenient err")
+	assert.Equalf(t, render.JSON, ct, "lenient content type")
+
+	render.SetNegotiatePolicy(render.Strict)
+	defer render.SetNegotiatePolicy(render.Lenient)
+	ct, err = render.NegotiateE(req)
+	assert.NotNilf(t, err, "strict err")
+	assert.Equalf(t, "not_acceptable", err.(errors.MetaError).Code(), "strict err code")
+	assert.Equalf(t, render.JSON, ct, "strict content type")
+	assert.Equalf(t, render.JSON, render.Negotiate(req), "negotiate never panics")
+
+	w := httptest.NewRecorder()
+	_, ok := render.NegotiateOrReject(w, req)
+	assert.Falsef(t, ok, "rejected")
+	assert.Equalf(t, 406, w.Code, "status")
+	assert.Equalf(t, "not_acceptable", w.Header().Get("X-Code"), "x-code")
+	assert.Containsf(t, w.Body.String(), `"supported":["application/json; charset=utf-8","application/problem+json"]`, "body")
+
+	req.Header.Set(render.AcceptHeader, "application/*")
+	w = httptest.NewRecorder()
+	ct, ok = render.NegotiateOrReject(w, req)
+	assert.Truef(t, ok, "accepted")
+	assert.Equalf(t, render.JSON, ct, "content type")
+	assert.Equalf(t, 0, w.Body.Len(), "nothing written")
 }
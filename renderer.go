@@ -0,0 +1,631 @@
+package render
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ccmonky/errors"
+	"github.com/ccmonky/inithook"
+	"go.uber.org/atomic"
+)
+
+// Renderer owns the registries, default content type, negotiater and app metadata used to render,
+// several `Renderer`s can be used in one binary without sharing state, e.g. two APIs with different envelopes:
+//
+//	v1 := render.New(render.WithApp("v1", "1.0.0"))
+//	v2 := render.New(render.WithApp("v2", "2.0.0"), render.WithRender(render.XML, std.XML{}), render.WithTransformer("", myTransformer))
+//
+//	v2.OK(w, r, v2.Negotiate(r), data)
+//
+// The package level registries(e.g. `Renders`) and functions(e.g. `Negotiate`, `ContentType.Render`)
+// belong to the default `Renderer`, see `Default`.
+type Renderer struct {
+	// Renders the renders registry
+	Renders *inithook.Map[ContentType, Render]
+
+	// ContentTypes the mapping of the name to `ContentType`
+	ContentTypes *inithook.Map[string, ContentType]
+
+	// Negotiaters the negotiaters registry, selected by name from request context, see `ContextWithNegotiater`
+	Negotiaters *inithook.Map[string, Negotiater]
+
+	// Transformers the `ResponseTransformer` registry, key is the template
+	Transformers *inithook.Map[string, ResponseTransformer]
+
+	// DefaultTemplates the default template of `ContentType`
+	DefaultTemplates *inithook.Map[ContentType, string]
+
+	// VendorTypes the mapping of the vendor media type to template
+	VendorTypes *inithook.Map[string, string]
+
+	// Exposures the exposure of template, see `Exposures`
+	Exposures *inithook.Map[string, Exposure]
+
+	// ExposedAttrs the allowlist of `errors.Map` attributes, see `ExposedAttrs`
+	ExposedAttrs *inithook.Map[string, Exposure]
+
+	// HeaderNames the header name of meta key, see `HeaderNames`
+	HeaderNames *inithook.Map[string, string]
+
+	// DisabledHeaders the meta keys whose headers are switched off for template, see `DisabledHeaders`
+	DisabledHeaders *inithook.Map[string, []string]
+
+	// Observers the observers of renders, see `Observers`
+	Observers *inithook.Map[string, Observer]
+
+	defaultContentType ContentType
+	appName            *atomic.String
+	appVersion         *atomic.String
+	negotiatePolicy    *atomic.Int32
+	clock              *atomic.Value
+	exposure           *atomic.Int32
+	incidentLogger     *atomic.Value
+	headerPrefix       *atomic.String
+	headerMaxLength    *atomic.Int32
+	requestIDExtractor *atomic.Value
+	requestIDGenerator *atomic.Value
+}
+
+// RendererOption `Renderer` creation option func
+type RendererOption func(*Renderer)
+
+// WithRender used to register(override) the render of content type
+func WithRender(ct ContentType, render Render) RendererOption {
+	return func(rd *Renderer) {
+		rd.Renders.MustSet(context.Background(), ct, render)
+	}
+}
+
+// WithRenders used to register(override) renders, e.g. copy the renders of default `Renderer`:
+//
+//	render.New(render.WithRenders(render.Renders.Map(ctx)))
+func WithRenders(renders map[ContentType]Render) RendererOption {
+	return func(rd *Renderer) {
+		for ct, render := range renders {
+			rd.Renders.MustSet(context.Background(), ct, render)
+		}
+	}
+}
+
+// WithContentTypeName used to register(override) the name of content type
+func WithContentTypeName(name string, ct ContentType) RendererOption {
+	return func(rd *Renderer) {
+		rd.ContentTypes.MustSet(context.Background(), strings.ToLower(name), ct)
+	}
+}
+
+// WithNegotiater used to specify the negotiater, default to `AcceptNegotiater`
+func WithNegotiater(negotiater Negotiater) RendererOption {
+	return func(rd *Renderer) {
+		rd.Negotiaters.MustSet(context.Background(), DefaultNegotiaterName, negotiater)
+	}
+}
+
+// WithTransformer used to register(override) the `ResponseTransformer` of template
+func WithTransformer(tmpl string, transformer ResponseTransformer) RendererOption {
+	return func(rd *Renderer) {
+		rd.Transformers.MustSet(context.Background(), tmpl, transformer)
+	}
+}
+
+// WithDefaultTemplate used to register(override) the default template of content type
+func WithDefaultTemplate(ct ContentType, tmpl string) RendererOption {
+	return func(rd *Renderer) {
+		rd.DefaultTemplates.MustSet(context.Background(), ct, tmpl)
+	}
+}
+
+// WithVendorType used to register(override) the template of vendor media type, see `VendorTypes`
+func WithVendorType(mediaType string, tmpl string) RendererOption {
+	return func(rd *Renderer) {
+		rd.VendorTypes.MustSet(context.Background(), mediaType, tmpl)
+	}
+}
+
+// WithDefaultContentType used to specify the default content type, default to `JSON`
+func WithDefaultContentType(ct ContentType) RendererOption {
+	return func(rd *Renderer) {
+		rd.defaultContentType = ct
+	}
+}
+
+// WithApp used to specify the app name and version rendered in response
+func WithApp(name, version string) RendererOption {
+	return func(rd *Renderer) {
+		rd.SetApp(name, version)
+	}
+}
+
+// WithClock used to specify the clock of the `timestamp` rendered in response, default to `time.Now`
+func WithClock(now func() time.Time) RendererOption {
+	return func(rd *Renderer) {
+		rd.SetClock(now)
+	}
+}
+
+// WithNegotiatePolicy used to specify the negotiate policy, default to `Lenient`
+func WithNegotiatePolicy(policy NegotiatePolicy) RendererOption {
+	return func(rd *Renderer) {
+		rd.SetNegotiatePolicy(policy)
+	}
+}
+
+// WithDefaultExposure used to specify the exposure of `Response`, default to `ExposeDebug`
+func WithDefaultExposure(e Exposure) RendererOption {
+	return func(rd *Renderer) {
+		rd.SetExposure(e)
+	}
+}
+
+// WithExposedAttr used to allow the `errors.Map` attribute exposed with exposure e, see `ExposedAttrs`
+func WithExposedAttr(key string, e Exposure) RendererOption {
+	return func(rd *Renderer) {
+		rd.ExposedAttrs.MustSet(context.Background(), key, e)
+	}
+}
+
+// WithTemplateExposure used to specify the exposure of template, see `Exposures`
+func WithTemplateExposure(tmpl string, e Exposure) RendererOption {
+	return func(rd *Renderer) {
+		rd.Exposures.MustSet(context.Background(), tmpl, e)
+	}
+}
+
+// WithIncidentLogger used to specify the logger of the errors hidden by `ExposePublic`, default to `log.Printf`
+func WithIncidentLogger(logger IncidentLogger) RendererOption {
+	return func(rd *Renderer) {
+		rd.SetIncidentLogger(logger)
+	}
+}
+
+// WithHeaderName used to register(override) the header name of meta key, see `HeaderNames`
+func WithHeaderName(key, name string) RendererOption {
+	return func(rd *Renderer) {
+		rd.HeaderNames.MustSet(context.Background(), key, name)
+	}
+}
+
+// WithHeaderPrefix used to specify the prefix of the meta header names, default to `X-`
+func WithHeaderPrefix(prefix string) RendererOption {
+	return func(rd *Renderer) {
+		rd.SetHeaderPrefix(prefix)
+	}
+}
+
+// WithHeaderMaxLength used to specify the max length of the meta header values, default to `DefaultHeaderMaxLength`
+func WithHeaderMaxLength(n int) RendererOption {
+	return func(rd *Renderer) {
+		rd.SetHeaderMaxLength(n)
+	}
+}
+
+// WithDisabledHeaders used to switch off the meta headers of template, see `DisabledHeaders`
+func WithDisabledHeaders(tmpl string, keys ...string) RendererOption {
+	return func(rd *Renderer) {
+		rd.DisabledHeaders.MustSet(context.Background(), tmpl, keys)
+	}
+}
+
+// WithRequestIDExtractor used to specify the `RequestIDExtractor`, default to `DefaultRequestIDExtractor`
+func WithRequestIDExtractor(extractor RequestIDExtractor) RendererOption {
+	return func(rd *Renderer) {
+		rd.SetRequestIDExtractor(extractor)
+	}
+}
+
+// WithRequestIDGenerator used to specify the generator of the request id if not extracted, default to the random 16 bytes hex
+func WithRequestIDGenerator(generate func() string) RendererOption {
+	return func(rd *Renderer) {
+		rd.SetRequestIDGenerator(generate)
+	}
+}
+
+// WithObserver used to register(override) the observer of renders, see `Observers`
+func WithObserver(name string, observer Observer) RendererOption {
+	return func(rd *Renderer) {
+		rd.Observers.MustSet(context.Background(), name, observer)
+	}
+}
+
+// New creates a new `Renderer` with the builtin renders, content type names, negotiater and transformers,
+// the renders registered by adapter packages(e.g. gin, unrolled) into the default `Renderer` are not included,
+// use `WithRender` or `WithRenders` to specify them.
+func New(opts ...RendererOption) *Renderer {
+	rd := newRenderer()
+	if err := rd.registerBuiltins(context.Background()); err != nil {
+		log.Panicln(errors.GetAllErrors(err))
+	}
+	for _, opt := range opts {
+		opt(rd)
+	}
+	return rd
+}
+
+// Default returns the default `Renderer` used by the package level functions
+func Default() *Renderer {
+	return defaultRenderer
+}
+
+// newRenderer creates a `Renderer` with empty registries
+func newRenderer() *Renderer {
+	return &Renderer{
+		Renders:            inithook.NewMap[ContentType, Render](),
+		ContentTypes:       inithook.NewMap[string, ContentType](),
+		Negotiaters:        inithook.NewMap[string, Negotiater](),
+		Transformers:       inithook.NewMap[string, ResponseTransformer](),
+		DefaultTemplates:   inithook.NewMap[ContentType, string](),
+		VendorTypes:        inithook.NewMap[string, string](),
+		Exposures:          inithook.NewMap[string, Exposure](),
+		ExposedAttrs:       inithook.NewMap[string, Exposure](),
+		HeaderNames:        inithook.NewMap[string, string](),
+		DisabledHeaders:    inithook.NewMap[string, []string](),
+		Observers:          inithook.NewMap[string, Observer](),
+		defaultContentType: JSON,
+		appName:            atomic.NewString(""),
+		appVersion:         atomic.NewString(""),
+		negotiatePolicy:    atomic.NewInt32(int32(Lenient)),
+		clock:              &atomic.Value{},
+		exposure:           atomic.NewInt32(int32(ExposeDebug)),
+		incidentLogger:     &atomic.Value{},
+		headerPrefix:       atomic.NewString(DefaultHeaderPrefix),
+		headerMaxLength:    atomic.NewInt32(DefaultHeaderMaxLength),
+		requestIDExtractor: &atomic.Value{},
+		requestIDGenerator: &atomic.Value{},
+	}
+}
+
+func (rd *Renderer) registerBuiltins(ctx context.Context) error {
+	err := rd.Renders.Register(ctx, JSON, jsonRender{})
+	err = errors.WithError(err, rd.Renders.Register(ctx, ProblemJSON, jsonRender{}))
+	err = errors.WithError(err, rd.Renders.Register(ctx, NDJSON, ndjsonRender{}))
+	err = errors.WithError(err, rd.Renders.Register(ctx, EventStream, sseRender{}))
+	err = errors.WithError(err, rd.DefaultTemplates.Register(ctx, ProblemJSON, ProblemTemplate))
+	err = errors.WithError(err, rd.Negotiaters.Register(ctx, DefaultNegotiaterName, AcceptNegotiater{}))
+	err = errors.WithError(err, rd.Transformers.Register(ctx, "", selfResponseTransformer))
+	err = errors.WithError(err, rd.Transformers.Register(ctx, ProblemTemplate, problemResponseTransformer))
+	for name, ct := range builtinContentTypes {
+		err = errors.WithError(err, rd.ContentTypes.Register(ctx, name, ct))
+	}
+	return err
+}
+
+// SetApp sets the app name and version rendered in response
+func (rd *Renderer) SetApp(name, version string) {
+	rd.appName.Store(name)
+	rd.appVersion.Store(version)
+}
+
+// App returns the app name and version
+func (rd *Renderer) App() (name, version string) {
+	return rd.appName.Load(), rd.appVersion.Load()
+}
+
+// SetClock sets the clock of the `timestamp` rendered in response, nil means `time.Now`
+func (rd *Renderer) SetClock(now func() time.Time) {
+	if now == nil {
+		now = time.Now
+	}
+	rd.clock.Store(now)
+}
+
+// Clock returns the clock of the `timestamp` rendered in response
+func (rd *Renderer) Clock() func() time.Time {
+	if now, ok := rd.clock.Load().(func() time.Time); ok {
+		return now
+	}
+	return time.Now
+}
+
+// DefaultContentType returns the default content type
+func (rd *Renderer) DefaultContentType() ContentType {
+	return rd.defaultContentType
+}
+
+// SetNegotiatePolicy sets the negotiate policy
+func (rd *Renderer) SetNegotiatePolicy(policy NegotiatePolicy) {
+	rd.negotiatePolicy.Store(int32(policy))
+}
+
+// NegotiatePolicy returns the negotiate policy
+func (rd *Renderer) NegotiatePolicy() NegotiatePolicy {
+	return NegotiatePolicy(rd.negotiatePolicy.Load())
+}
+
+// SetExposure sets the exposure of `Response`, it's overridden by the exposures of template and request
+func (rd *Renderer) SetExposure(e Exposure) {
+	rd.exposure.Store(int32(e))
+}
+
+// Exposure returns the exposure of `Response`
+func (rd *Renderer) Exposure() Exposure {
+	return Exposure(rd.exposure.Load())
+}
+
+// SetIncidentLogger sets the logger of the errors hidden by `ExposePublic`, nil means `log.Printf`
+func (rd *Renderer) SetIncidentLogger(logger IncidentLogger) {
+	if logger == nil {
+		logger = logIncident
+	}
+	rd.incidentLogger.Store(logger)
+}
+
+// logIncident logs the error hidden by `ExposePublic` with the incident id
+func (rd *Renderer) logIncident(incident string, err error) {
+	if logger, ok := rd.incidentLogger.Load().(IncidentLogger); ok {
+		logger(incident, err)
+		return
+	}
+	logIncident(incident, err)
+}
+
+// Ready tells if the render of content type is registered, the vendor media type is ready if its base render registered
+func (rd *Renderer) Ready(ct ContentType) bool {
+	_, err := rd.getRender(context.Background(), ct)
+	return err == nil
+}
+
+// getRender returns the render of content type, or the base render of vendor media type
+func (rd *Renderer) getRender(ctx context.Context, ct ContentType) (Render, error) {
+	render, err := rd.Renders.Get(ctx, ct)
+	if err == nil {
+		return render, nil
+	}
+	if _, base, ok := rd.vendorType(ctx, ct); ok {
+		return rd.Renders.Get(ctx, base)
+	}
+	return nil, err
+}
+
+// NewResponse creates a new *Response instance which renders the app metadata of rd,
+// and returns it or it's variant transformed by rd's `Transformers`
+func (rd *Renderer) NewResponse(data any, opts ...ResponseOption) ResponseInterface {
+	rp := &Response{
+		Data:     data,
+		renderer: rd,
+	}
+	for _, opt := range opts {
+		opt(rp)
+	}
+	if rp.MetaError == nil {
+		rp.MetaError = errors.OK
+	}
+	transformer, err := rd.Transformers.Get(context.Background(), rp.Template)
+	if err != nil {
+		log.Panicf("response transformer %s not found", rp.Template)
+	}
+	return transformer(rp)
+}
+
+// render renders data with the render of content type
+func (rd *Renderer) render(w http.ResponseWriter, ct ContentType, data any, opts ...Option) error {
+	render, err := rd.getRender(context.TODO(), ct)
+	if err != nil {
+		return errors.WithMessagef(err, "get render failed for %v", ct)
+	}
+	opts = append([]Option{withRenderer(rd)}, opts...)
+	options := newRenderOptions(render, opts...)
+	if options.Unbuffered {
+		if s, ok := render.(Streamer); ok && s.Streaming() {
+			return ct.render(w, render, data, opts...)
+		}
+		if options.unbufferedNotModified(w.Header(), data) {
+			writeNotModified(w)
+			return nil
+		}
+		return ct.render(w, render, data, opts...)
+	}
+	bw := newBufferedWriter(w)
+	defer bw.release()
+	options.validators(bw.header)
+	err = ct.render(bw, render, data, opts...)
+	if err != nil {
+		rd.renderError(w, ct, render, err)
+		return err
+	}
+	options.conditional(bw)
+	return bw.commit(w)
+}
+
+// renderError renders the 500 `Response` for the encoding error, if failed again, fallback to plain text
+func (rd *Renderer) renderError(w http.ResponseWriter, ct ContentType, render Render, err error) {
+	bw := newBufferedWriter(w)
+	defer bw.release()
+	rp := rd.NewResponse(nil, E(err))
+	if ct.render(bw, render, rp) != nil || bw.commit(w) != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		rp = nil
+	}
+	if ow, ok := w.(*observedWriter); ok {
+		ow.fellBack, ow.fallback = true, rp
+	}
+}
+
+// OK renders data with content type, see `ContentType.OK`
+func (rd *Renderer) OK(w http.ResponseWriter, r *http.Request, ct ContentType, data any, opts ...Option) error {
+	if r != nil {
+		opts = append([]Option{ForRequest(r)}, opts...)
+	}
+	if r != nil && rd.isStreaming(ct) {
+		return rd.Render(w, ct, data, opts...)
+	}
+	if _, ok := data.(ResponseInterface); !ok && r != nil {
+		rp := rd.NewResponse(data, T(rd.Template(r, ct)), rd.fromRequest(r))
+		return rd.Render(w, ct, rp, opts...)
+	}
+	return rd.Render(w, ct, data, opts...)
+}
+
+// Err renders err with content type, see `ContentType.Err`
+func (rd *Renderer) Err(w http.ResponseWriter, r *http.Request, ct ContentType, err error, opts ...Option) error {
+	if r != nil {
+		opts = append([]Option{ForRequest(r)}, opts...)
+	}
+	return rd.Render(w, ct, rd.NewResponse(nil, E(err), T(rd.Template(r, ct)), rd.fromRequest(r)), opts...)
+}
+
+// Template returns the template of content type for request, see `ContentType.Template`
+func (rd *Renderer) Template(r *http.Request, ct ContentType) string {
+	if r != nil {
+		if tmpl := r.Header.Get(TemplateHeader); tmpl != "" {
+			return tmpl
+		}
+	}
+	if tmpl, _, ok := rd.vendorType(context.Background(), ct); ok {
+		return tmpl
+	}
+	tmpl, _ := rd.DefaultTemplates.Get(context.Background(), ct)
+	return tmpl
+}
+
+// ContentType returns the content type for name
+func (rd *Renderer) ContentType(name string) (ContentType, error) {
+	return rd.ContentTypes.Get(context.Background(), strings.ToLower(name))
+}
+
+// Negotiate see `Negotiate`
+func (rd *Renderer) Negotiate(r *http.Request) ContentType {
+	ct, _ := rd.NegotiateE(r)
+	return ct
+}
+
+// NegotiateE see `NegotiateE`
+func (rd *Renderer) NegotiateE(r *http.Request) (ContentType, error) {
+	if r == nil {
+		return rd.defaultContentType, nil
+	}
+	ctx := r.Context()
+	fallback := rd.fallback(ctx)
+	if ct, ok := FormatFromContext(ctx); ok {
+		if !rd.allowed(ctx, ct) {
+			return fallback, errors.WithError(fmt.Errorf("format %v not allowed", ct), NotAcceptable)
+		}
+		return ct, nil
+	}
+	header := r.Header.Get(AcceptHeader)
+	if header == "" {
+		return fallback, nil
+	}
+	negotiaterName := DefaultNegotiaterName
+	if name, ok := NegotiaterFromContext(ctx); ok {
+		negotiaterName = name
+	}
+	negotiater, err := rd.Negotiaters.Get(ctx, negotiaterName)
+	if err != nil {
+		return fallback, errors.WithMessagef(err, "get negotiater %s failed", negotiaterName)
+	}
+	var ctypes []string
+	for _, k := range rd.candidates(ctx) {
+		ctypes = append(ctypes, string(k))
+	}
+	ctype, err := negotiater.Negotiate(header, ctypes...)
+	if err != nil {
+		if rd.NegotiatePolicy() == Lenient {
+			return fallback, nil
+		}
+		return fallback, errors.WithError(err, NotAcceptable)
+	}
+	ct := ContentType(ctype)
+	if _, err := rd.getRender(ctx, ct); err != nil {
+		return fallback, fmt.Errorf("render not found for negotiated content type %v", ct)
+	}
+	return ct, nil
+}
+
+// NegotiateOrReject see `NegotiateOrReject`
+func (rd *Renderer) NegotiateOrReject(w http.ResponseWriter, r *http.Request) (ContentType, bool) {
+	ct, err := rd.NegotiateE(r)
+	if err == nil {
+		return ct, true
+	}
+	rd.reject(w, r, err)
+	return ct, false
+}
+
+// reject renders the negotiation error with the default content type, the data lists the supported content types
+func (rd *Renderer) reject(w http.ResponseWriter, r *http.Request, err error) {
+	var supported []string
+	for _, k := range rd.candidates(r.Context()) {
+		supported = append(supported, string(k))
+	}
+	data := map[string]any{
+		"supported": supported,
+	}
+	rd.Render(w, rd.fallback(r.Context()), rd.NewResponse(data, E(err), T(r.Header.Get(TemplateHeader)), rd.fromRequest(r)))
+}
+
+// supportedContentTypes returns the default candidates, the streaming renders(see `Streamer`) are excluded,
+// they are only selected if allowed by the route(see `Negotiation`) or by format(see `Format`)
+func (rd *Renderer) supportedContentTypes(ctx context.Context) []ContentType {
+	var keys []ContentType
+	for _, ct := range rd.Renders.Keys(ctx) {
+		if !rd.isStreaming(ct) {
+			keys = append(keys, ct)
+		}
+	}
+	sortContentTypes(keys, rd.defaultContentType)
+	return append(keys, rd.vendorContentTypes(ctx)...)
+}
+
+// isStreaming tells if the render of content type is a `Streamer`
+func (rd *Renderer) isStreaming(ct ContentType) bool {
+	render, err := rd.getRender(context.Background(), ct)
+	if err != nil {
+		return false
+	}
+	s, ok := render.(Streamer)
+	return ok && s.Streaming()
+}
+
+// withRenderer makes renders aware of the `Renderer`, e.g. sse gets data render from it
+func withRenderer(rd *Renderer) Option {
+	return func(o any) {
+		if options, ok := o.(rendererAware); ok {
+			options.setRenderer(rd)
+		}
+	}
+}
+
+// rendererAware implemented by options of renderer aware renders
+type rendererAware interface {
+	setRenderer(*Renderer)
+}
+
+var builtinContentTypes = map[string]ContentType{
+	"":          JSON,
+	"json":      JSON,
+	"jsonascii": JSONASCII,
+	"jsonp":     JSONP,
+	"html":      HTML,
+	"text":      Text,
+	"protobuf":  PROTOBUF,
+	"binary":    Binary,
+	"yaml":      YAML,
+	"toml":      TOML,
+	"xml":       XML,
+	"msgpack":   MSGPACK,
+	"xhtml":     XHTML,
+	"problem":   ProblemJSON,
+	"ndjson":    NDJSON,
+	"sse":       EventStream,
+}
+
+// defaultRenderer the default `Renderer` whose registries are the package level ones
+var defaultRenderer = func() *Renderer {
+	rd := newRenderer()
+	rd.Renders = Renders
+	rd.ContentTypes = ContentTypes
+	rd.Negotiaters = Negotiaters
+	rd.Transformers = Transformers
+	rd.DefaultTemplates = DefaultTemplates
+	rd.VendorTypes = VendorTypes
+	rd.Exposures = Exposures
+	rd.ExposedAttrs = ExposedAttrs
+	rd.HeaderNames = HeaderNames
+	rd.DisabledHeaders = DisabledHeaders
+	rd.Observers = Observers
+	return rd
+}()
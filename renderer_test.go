@@ -0,0 +1,83 @@
+package render_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ccmonky/errors"
+	"github.com/ccmonky/render"
+	"github.com/stretchr/testify/assert"
+)
+
+type plainResponse struct {
+	*render.Response
+}
+
+func (pr plainResponse) Body() any {
+	return map[string]any{"ok": pr.MetaError == errors.OK, "result": pr.Data}
+}
+
+func TestRenderer(t *testing.T) {
+	text := render.RenderFunc(func(w http.ResponseWriter, data any, opts ...render.Option) error {
+		_, err := w.Write([]byte("text"))
+		return err
+	})
+	v1 := render.New(render.WithApp("v1", "1.0.0"))
+	v2 := render.New(
+		render.WithApp("v2", "2.0.0"),
+		render.WithRender(render.Text, text),
+		render.WithTransformer("", func(rp *render.Response) render.ResponseInterface {
+			return plainResponse{rp}
+		}),
+		render.WithDefaultContentType(render.Text),
+	)
+	assert.Truef(t, v2.Ready(render.Text), "v2 text")
+	assert.Falsef(t, v1.Ready(render.Text), "v1 text")
+	assert.Falsef(t, render.Default() == v1, "default")
+
+	r := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	err := v1.OK(w, r, render.JSON, 1)
+	assert.Nilf(t, err, "v1 err")
+	assert.Equalf(t, "v1", w.Header().Get("X-App"), "v1 app")
+	var body map[string]any
+	json.Unmarshal(w.Body.Bytes(), &body)
+	assert.Equalf(t, "1.0.0", body["version"], "v1 version")
+
+	w = httptest.NewRecorder()
+	err = v2.OK(w, r, render.JSON, 2)
+	assert.Nilf(t, err, "v2 err")
+	assert.Equalf(t, "v2", w.Header().Get("X-App"), "v2 app")
+	assert.JSONEqf(t, `{"ok":true,"result":2}`, w.Body.String(), "v2 body")
+
+	r.Header.Set("Accept", "text/plain")
+	assert.Equalf(t, render.Text, v2.Negotiate(r), "v2 negotiate")
+	assert.Equalf(t, render.JSON, v1.Negotiate(r), "v1 negotiate")
+	r.Header.Set("Accept", "image/png")
+	assert.Equalf(t, render.Text, v2.Negotiate(r), "v2 default")
+
+	ct, err := v2.ContentType("problem")
+	assert.Nilf(t, err, "content type name")
+	assert.Equalf(t, render.ProblemJSON, ct, "content type name")
+
+	w = httptest.NewRecorder()
+	err = v1.Err(w, nil, render.ProblemJSON, errors.NotFound)
+	assert.Nilf(t, err, "v1 problem err")
+	assert.Equalf(t, 404, w.Code, "v1 problem status")
+	assert.Equalf(t, "problem", w.Header().Get("X-Render-Template"), "v1 problem template")
+}
+
+func TestRendererParallel(t *testing.T) {
+	for _, name := range []string{"a", "b", "c"} {
+		name := name
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			rd := render.New(render.WithApp(name, name))
+			w := httptest.NewRecorder()
+			rd.Render(w, render.JSON, rd.NewResponse(nil))
+			assert.Equalf(t, name, w.Header().Get("X-App"), "app")
+		})
+	}
+}
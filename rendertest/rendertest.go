@@ -0,0 +1,345 @@
+// Package rendertest provides utilities to test the responses rendered by render, e.g.
+//
+//	func TestGetUser(t *testing.T) {
+//		rendertest.SetClock(t, nil, rendertest.NewClock(time.Unix(0, 0)).Now)
+//		rendertest.SetApp(t, nil, "myapp", "0.3.0")
+//
+//		rec := rendertest.NewRecorder()
+//		handler.ServeHTTP(rec, httptest.NewRequest("GET", "/users/1", nil))
+//		rec.Assert(t).Status(404).Meta(ErrUserNotFound).Template("").Golden("get_user_not_found")
+//	}
+//
+// run `go test -rendertest.update` to update the golden files.
+package rendertest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ccmonky/errors"
+	"github.com/ccmonky/render"
+)
+
+// GoldenDir the directory of golden files
+const GoldenDir = "testdata"
+
+// VolatileFields the body members ignored by assertions and golden files
+var VolatileFields = []string{"timestamp", "request_id"}
+
+// VolatileHeaders the meta headers(see `render.Renderer.HeaderName`) ignored by golden files
+var VolatileHeaders = []string{render.MetaRequestID}
+
+// update used to update the golden files instead of comparing
+var update = flag.Bool("rendertest.update", false, "update the golden files of rendertest")
+
+// Clock is a fake clock, use `SetClock` to inject it into a `Renderer`
+type Clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewClock creates a new `Clock` stopped at now
+func NewClock(now time.Time) *Clock {
+	return &Clock{now: now}
+}
+
+// Now returns the current time of clock
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance moves the clock forward by d
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// SetClock injects the clock into renderer(nil means `render.Default()`), it's restored when the test finished
+func SetClock(t testing.TB, rd *render.Renderer, now func() time.Time) {
+	if rd == nil {
+		rd = render.Default()
+	}
+	old := rd.Clock()
+	rd.SetClock(now)
+	t.Cleanup(func() {
+		rd.SetClock(old)
+	})
+}
+
+// SetApp injects the app info into renderer(nil means `render.Default()`), it's restored when the test finished
+func SetApp(t testing.TB, rd *render.Renderer, name, version string) {
+	if rd == nil {
+		rd = render.Default()
+	}
+	oldName, oldVersion := rd.App()
+	rd.SetApp(name, version)
+	t.Cleanup(func() {
+		rd.SetApp(oldName, oldVersion)
+	})
+}
+
+// ForEachContentType runs fn as subtest for each non streaming content type registered in renderer(nil means `render.Default()`),
+// it's used to test the `Transformers` across the content types in a table-driven way
+func ForEachContentType(t *testing.T, rd *render.Renderer, fn func(t *testing.T, ct render.ContentType)) {
+	if rd == nil {
+		rd = render.Default()
+	}
+	ctx := context.Background()
+	cts := rd.Renders.Keys(ctx)
+	render.SortContentTypes(cts)
+	for _, ct := range cts {
+		r, _ := rd.Renders.Get(ctx, ct)
+		if s, ok := r.(render.Streamer); ok && s.Streaming() {
+			continue
+		}
+		ct := ct
+		t.Run(string(ct), func(t *testing.T) {
+			fn(t, ct)
+		})
+	}
+}
+
+// Recorder records the response, it's an `httptest.ResponseRecorder` with assertions
+type Recorder struct {
+	*httptest.ResponseRecorder
+}
+
+// NewRecorder creates a new `Recorder`
+func NewRecorder() *Recorder {
+	return &Recorder{
+		ResponseRecorder: httptest.NewRecorder(),
+	}
+}
+
+// Envelope decodes the body with the decoder of response content type registered in `render.Decoders`,
+// the `VolatileFields` are removed, returns error if not decodable as an object
+func (rec *Recorder) Envelope() (map[string]any, error) {
+	var v any
+	if err := decode(rec.Header().Get(render.ContentTypeHeader), rec.Body.Bytes(), &v); err != nil {
+		return nil, err
+	}
+	m, ok := normalize(v).(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("body is %T, not object", v)
+	}
+	for _, field := range VolatileFields {
+		delete(m, field)
+	}
+	return m, nil
+}
+
+// Assert returns the assertions of the recorded response
+func (rec *Recorder) Assert(t testing.TB) *Assertion {
+	return &Assertion{
+		t:   t,
+		rec: rec,
+	}
+}
+
+// Assertion asserts the recorded response, the failures are reported by `t.Errorf`
+type Assertion struct {
+	t   testing.TB
+	rec *Recorder
+}
+
+// Status asserts the status
+func (a *Assertion) Status(status int) *Assertion {
+	a.t.Helper()
+	if a.rec.Code != status {
+		a.t.Errorf("status: expected %d, got %d", status, a.rec.Code)
+	}
+	return a
+}
+
+// Header asserts the header value
+func (a *Assertion) Header(key, value string) *Assertion {
+	a.t.Helper()
+	if got := a.rec.Header().Get(key); got != value {
+		a.t.Errorf("header %s: expected %q, got %q", key, value, got)
+	}
+	return a
+}
+
+// Code asserts the `X-Code` header(named by the default `render.Renderer`)
+func (a *Assertion) Code(code string) *Assertion {
+	a.t.Helper()
+	return a.metaHeader(render.MetaCode, code)
+}
+
+// Message asserts the `X-Message` header(named by the default `render.Renderer`)
+func (a *Assertion) Message(message string) *Assertion {
+	a.t.Helper()
+	return a.metaHeader(render.MetaMessage, message)
+}
+
+func (a *Assertion) metaHeader(key, value string) *Assertion {
+	a.t.Helper()
+	name := render.Default().HeaderName(key)
+	if got := render.DecodeHeaderValue(a.rec.Header().Get(name)); got != value {
+		a.t.Errorf("header %s: expected %q, got %q", name, value, got)
+	}
+	return a
+}
+
+// Template asserts the `X-Render-Template` header
+func (a *Assertion) Template(tmpl string) *Assertion {
+	a.t.Helper()
+	return a.Header(render.TemplateHeader, tmpl)
+}
+
+// Meta asserts the status, code and message are those of the `MetaError`
+func (a *Assertion) Meta(me errors.MetaError) *Assertion {
+	a.t.Helper()
+	return a.Status(errors.StatusAttr.Get(me)).Code(me.Code()).Message(me.Message())
+}
+
+// Data asserts the `data` member of envelope equals to data, they are compared after normalized by JSON round trip
+func (a *Assertion) Data(data any) *Assertion {
+	a.t.Helper()
+	m, err := a.rec.Envelope()
+	if err != nil {
+		a.t.Errorf("data: %v", err)
+		return a
+	}
+	expected, err := roundTrip(data)
+	if err != nil {
+		a.t.Errorf("data: normalize expected failed: %v", err)
+		return a
+	}
+	if got := m["data"]; !reflect.DeepEqual(expected, got) {
+		a.t.Errorf("data: expected %#v, got %#v", expected, got)
+	}
+	return a
+}
+
+// Golden asserts the snapshot of response equals to the golden file `testdata/<name>.golden`,
+// the snapshot includes the status, the `Content-Type` and `X-` headers without `VolatileHeaders`, and the body without `VolatileFields`,
+// run `go test -rendertest.update` to update the golden files
+func (a *Assertion) Golden(name string) *Assertion {
+	a.t.Helper()
+	snapshot := a.rec.snapshot()
+	path := filepath.Join(GoldenDir, name+".golden")
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			a.t.Fatalf("golden: %v", err)
+		}
+		if err := os.WriteFile(path, snapshot, 0o644); err != nil {
+			a.t.Fatalf("golden: %v", err)
+		}
+		return a
+	}
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		a.t.Errorf("golden: %v, run with -rendertest.update to create it", err)
+		return a
+	}
+	if !bytes.Equal(expected, snapshot) {
+		a.t.Errorf("golden %s mismatch:\nexpected:\n%s\ngot:\n%s", path, expected, snapshot)
+	}
+	return a
+}
+
+func (rec *Recorder) snapshot() []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "status: %d\n", rec.Code)
+	header := rec.Header()
+	keys := make([]string, 0, len(header))
+	volatile := make(map[string]bool, len(VolatileHeaders))
+	for _, key := range VolatileHeaders {
+		volatile[render.Default().HeaderName(key)] = true
+	}
+	for key := range header {
+		if volatile[key] {
+			continue
+		}
+		if key == render.ContentTypeHeader || strings.HasPrefix(key, "X-") {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		for _, value := range header[key] {
+			fmt.Fprintf(&buf, "%s: %s\n", key, value)
+		}
+	}
+	buf.WriteString("\n")
+	if m, err := rec.Envelope(); err == nil {
+		data, _ := json.MarshalIndent(m, "", "  ")
+		buf.Write(data)
+		buf.WriteString("\n")
+	} else {
+		buf.Write(rec.Body.Bytes())
+	}
+	return buf.Bytes()
+}
+
+// decode decodes body with the request decoder of content type
+func decode(contentType string, body []byte, v any) error {
+	decoder, ok := render.GetDecoder(context.Background(), render.ContentType(contentType))
+	if !ok {
+		return fmt.Errorf("no decoder for %q", contentType)
+	}
+	r, err := http.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	r.Header.Set(render.ContentTypeHeader, contentType)
+	return decoder.Decode(r, v)
+}
+
+// normalize converts the decoded value to JSON compatible, e.g. `map[any]any` of yaml
+func normalize(v any) any {
+	switch v := v.(type) {
+	case map[any]any:
+		m := make(map[string]any, len(v))
+		for k, value := range v {
+			m[fmt.Sprint(k)] = normalize(value)
+		}
+		return m
+	case map[string]any:
+		for k, value := range v {
+			v[k] = normalize(value)
+		}
+		return v
+	case []any:
+		for i, value := range v {
+			v[i] = normalize(value)
+		}
+		return v
+	}
+	if v == nil {
+		return nil
+	}
+	normalized, err := roundTrip(v)
+	if err != nil {
+		return v
+	}
+	return normalized
+}
+
+// roundTrip normalizes v by JSON round trip
+func roundTrip(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var normalized any
+	err = json.Unmarshal(data, &normalized)
+	return normalized, err
+}
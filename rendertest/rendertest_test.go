@@ -0,0 +1,84 @@
+package rendertest_test
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ccmonky/errors"
+	"github.com/ccmonky/render"
+	"github.com/ccmonky/render/rendertest"
+	"github.com/stretchr/testify/assert"
+)
+
+type user struct {
+	Name string `json:"name"`
+	Age  int    `json:"age"`
+}
+
+func TestClock(t *testing.T) {
+	clock := rendertest.NewClock(time.Unix(100, 0))
+	rd := render.New()
+	rendertest.SetClock(t, rd, clock.Now)
+	body := rd.NewResponse(nil).Body().(map[string]any)
+	assert.Equalf(t, int64(100), body["timestamp"], "timestamp")
+	clock.Advance(time.Minute)
+	body = rd.NewResponse(nil).Body().(map[string]any)
+	assert.Equalf(t, int64(160), body["timestamp"], "advanced timestamp")
+}
+
+func TestSetApp(t *testing.T) {
+	rd := render.New(render.WithApp("old", "0.0.1"))
+	t.Run("inject", func(t *testing.T) {
+		rendertest.SetApp(t, rd, "myapp", "0.3.0")
+		name, version := rd.App()
+		assert.Equalf(t, "myapp", name, "name")
+		assert.Equalf(t, "0.3.0", version, "version")
+	})
+	name, version := rd.App()
+	assert.Equalf(t, "old", name, "restored name")
+	assert.Equalf(t, "0.0.1", version, "restored version")
+}
+
+func TestAssertion(t *testing.T) {
+	rendertest.SetClock(t, nil, rendertest.NewClock(time.Unix(0, 0)).Now)
+	rendertest.SetApp(t, nil, "myapp", "0.3.0")
+
+	rec := rendertest.NewRecorder()
+	render.JSON.OK(rec, httptest.NewRequest("GET", "/", nil), user{Name: "a", Age: 1})
+	rec.Assert(t).Status(200).Meta(errors.OK).Template("").Data(user{Name: "a", Age: 1})
+	m, err := rec.Envelope()
+	assert.Nilf(t, err, "envelope")
+	assert.NotContainsf(t, m, "timestamp", "volatile")
+
+	rec = rendertest.NewRecorder()
+	render.JSON.Err(rec, httptest.NewRequest("GET", "/", nil), errors.NotFound)
+	rec.Assert(t).Meta(errors.NotFound).Data(nil).Golden("not_found")
+
+	ft := &fakeT{TB: t}
+	rec.Assert(ft).Status(200).Code("xxx").Data(1)
+	assert.Equalf(t, 3, ft.failures, "failures")
+}
+
+func TestForEachContentType(t *testing.T) {
+	rd := render.New()
+	var cts []render.ContentType
+	rendertest.ForEachContentType(t, rd, func(t *testing.T, ct render.ContentType) {
+		cts = append(cts, ct)
+		rec := rendertest.NewRecorder()
+		rd.Err(rec, httptest.NewRequest("GET", "/", nil), ct, errors.InvalidArgument)
+		rec.Assert(t).Meta(errors.InvalidArgument)
+	})
+	assert.Equalf(t, []render.ContentType{render.JSON, render.ProblemJSON}, cts, "content types")
+}
+
+type fakeT struct {
+	testing.TB
+	failures int
+}
+
+func (ft *fakeT) Helper() {}
+
+func (ft *fakeT) Errorf(format string, args ...any) {
+	ft.failures++
+}
@@ -0,0 +1,181 @@
+package render
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// MetaRequestID the key of the request id meta header, i.e. `X-Request-Id`
+	MetaRequestID = "request-id"
+
+	// RequestIDHeader `X-Request-Id` request header name
+	RequestIDHeader = "X-Request-Id"
+
+	// TraceparentHeader W3C trace context `traceparent` request header name
+	TraceparentHeader = "traceparent"
+)
+
+// RequestIDExtractor extracts the request id from request, used to correlate the response with the logs,
+// implement it to hook the tracing library in, e.g.
+//
+//	render.SetRequestIDExtractor(render.RequestIDExtractorFunc(func(r *http.Request) (string, bool) {
+//		span := trace.SpanFromContext(r.Context())
+//		return span.SpanContext().TraceID().String(), span.SpanContext().IsValid()
+//	}))
+type RequestIDExtractor interface {
+	RequestID(r *http.Request) (string, bool)
+}
+
+// RequestIDExtractorFunc function implements `RequestIDExtractor`
+type RequestIDExtractorFunc func(r *http.Request) (string, bool)
+
+// RequestID implement `RequestIDExtractor`
+func (f RequestIDExtractorFunc) RequestID(r *http.Request) (string, bool) {
+	return f(r)
+}
+
+var (
+	// ContextRequestID extracts the request id from request context, see `ContextWithRequestID`
+	ContextRequestID = RequestIDExtractorFunc(func(r *http.Request) (string, bool) {
+		return RequestIDFromContext(r.Context())
+	})
+
+	// HeaderRequestID extracts the request id from `X-Request-Id` header
+	HeaderRequestID = RequestIDExtractorFunc(func(r *http.Request) (string, bool) {
+		id := r.Header.Get(RequestIDHeader)
+		return id, id != ""
+	})
+
+	// TraceparentRequestID extracts the trace id of W3C `traceparent` header as the request id
+	TraceparentRequestID = RequestIDExtractorFunc(func(r *http.Request) (string, bool) {
+		return parseTraceparent(r.Header.Get(TraceparentHeader))
+	})
+
+	// DefaultRequestIDExtractor extracts the request id from request context, `X-Request-Id`, or `traceparent` in order
+	DefaultRequestIDExtractor = ChainRequestIDExtractors(ContextRequestID, HeaderRequestID, TraceparentRequestID)
+)
+
+// ChainRequestIDExtractors returns the `RequestIDExtractor` which returns the first request id extracted
+func ChainRequestIDExtractors(extractors ...RequestIDExtractor) RequestIDExtractor {
+	return RequestIDExtractorFunc(func(r *http.Request) (string, bool) {
+		for _, extractor := range extractors {
+			if id, ok := extractor.RequestID(r); ok {
+				return id, true
+			}
+		}
+		return "", false
+	})
+}
+
+// SetRequestIDExtractor sets the `RequestIDExtractor` of the default `Renderer`
+func SetRequestIDExtractor(extractor RequestIDExtractor) {
+	defaultRenderer.SetRequestIDExtractor(extractor)
+}
+
+// WithRequestID used to specify the request id of `Response`, it's rendered as the `request_id` member and `X-Request-Id` header
+func WithRequestID(id string) ResponseOption {
+	return func(rp *Response) {
+		rp.RequestID = id
+	}
+}
+
+// ContextWithRequestID returns the context carries the request id
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request id carried by context
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDKey{}).(string)
+	return id, ok && id != ""
+}
+
+// Correlate returns the middleware which resolves the request id(see `Renderer.RequestID`), stores it into request context
+// and sets the `X-Request-Id` response header, so the handlers can log it by `RequestIDFromContext`, see `Renderer.Correlate`
+func Correlate() func(http.Handler) http.Handler {
+	return defaultRenderer.Correlate()
+}
+
+// Correlate see `Correlate`
+func (rd *Renderer) Correlate() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := rd.RequestID(r)
+			w.Header().Set(rd.HeaderName(MetaRequestID), SanitizeHeaderValue(id, rd.HeaderMaxLength()))
+			next.ServeHTTP(w, r.WithContext(ContextWithRequestID(r.Context(), id)))
+		})
+	}
+}
+
+// SetRequestIDExtractor sets the `RequestIDExtractor`, nil means `DefaultRequestIDExtractor`
+func (rd *Renderer) SetRequestIDExtractor(extractor RequestIDExtractor) {
+	if extractor == nil {
+		extractor = DefaultRequestIDExtractor
+	}
+	rd.requestIDExtractor.Store(extractor)
+}
+
+// SetRequestIDGenerator sets the generator of the request id if not extracted, nil means the random 16 bytes hex
+func (rd *Renderer) SetRequestIDGenerator(generate func() string) {
+	if generate == nil {
+		generate = newRequestID
+	}
+	rd.requestIDGenerator.Store(generate)
+}
+
+// RequestID returns the request id extracted by the `RequestIDExtractor`, or generated if absent
+func (rd *Renderer) RequestID(r *http.Request) string {
+	extractor, ok := rd.requestIDExtractor.Load().(RequestIDExtractor)
+	if !ok {
+		extractor = DefaultRequestIDExtractor
+	}
+	if id, ok := extractor.RequestID(r); ok {
+		return id
+	}
+	if generate, ok := rd.requestIDGenerator.Load().(func() string); ok {
+		return generate()
+	}
+	return newRequestID()
+}
+
+// fromRequest used to specify the exposure and request id of `Response` from request
+func (rd *Renderer) fromRequest(r *http.Request) ResponseOption {
+	return func(rp *Response) {
+		if r == nil {
+			return
+		}
+		requestExposure(r)(rp)
+		if rp.RequestID == "" {
+			rp.RequestID = rd.RequestID(r)
+		}
+	}
+}
+
+// parseTraceparent returns the trace id of `traceparent`, i.e. `version-traceid-parentid-flags`
+func parseTraceparent(v string) (string, bool) {
+	parts := strings.Split(strings.TrimSpace(v), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || len(parts[1]) != 32 {
+		return "", false
+	}
+	traceID := strings.ToLower(parts[1])
+	if _, err := hex.DecodeString(traceID); err != nil || strings.Trim(traceID, "0") == "" {
+		return "", false
+	}
+	return traceID, true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(b)
+}
+
+type requestIDKey struct{}
@@ -0,0 +1,85 @@
+package render_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ccmonky/errors"
+	"github.com/ccmonky/render"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestID(t *testing.T) {
+	rd := render.New(render.WithRequestIDGenerator(func() string { return "generated" }))
+	r := httptest.NewRequest("GET", "/", nil)
+	assert.Equalf(t, "generated", rd.RequestID(r), "generated")
+
+	r.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	assert.Equalf(t, "4bf92f3577b34da6a3ce929d0e0e4736", rd.RequestID(r), "traceparent")
+	r.Header.Set(render.RequestIDHeader, "req-1")
+	assert.Equalf(t, "req-1", rd.RequestID(r), "header")
+	r = r.WithContext(render.ContextWithRequestID(r.Context(), "ctx-1"))
+	assert.Equalf(t, "ctx-1", rd.RequestID(r), "context")
+
+	for _, tp := range []string{
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e47-00f067aa0ba902b7-01",
+		"00-zzf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	} {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("traceparent", tp)
+		assert.Equalf(t, "generated", rd.RequestID(r), "invalid traceparent %s", tp)
+	}
+
+	rd.SetRequestIDExtractor(render.RequestIDExtractorFunc(func(r *http.Request) (string, bool) {
+		return "span-1", true
+	}))
+	assert.Equalf(t, "span-1", rd.RequestID(r), "custom extractor")
+}
+
+func TestRequestIDEnvelope(t *testing.T) {
+	rd := render.New()
+	handler := rd.Correlate()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, ok := render.RequestIDFromContext(r.Context())
+		assert.Truef(t, ok, "context request id")
+		if r.URL.Path == "/ok" {
+			rd.OK(w, r, render.JSON, "data")
+			return
+		}
+		assert.Equalf(t, "req-1", id, "correlated")
+		rd.Err(w, r, render.JSON, errors.NotFound)
+	}))
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/err", nil)
+	r.Header.Set(render.RequestIDHeader, "req-1")
+	handler.ServeHTTP(w, r)
+	var body map[string]any
+	assert.Nilf(t, json.Unmarshal(w.Body.Bytes(), &body), "unmarshal")
+	assert.Equalf(t, "req-1", body["request_id"], "error body")
+	assert.Equalf(t, "req-1", w.Header().Get(render.RequestIDHeader), "error header")
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/ok", nil))
+	body = nil
+	assert.Nilf(t, json.Unmarshal(w.Body.Bytes(), &body), "unmarshal")
+	id := w.Header().Get(render.RequestIDHeader)
+	assert.Equalf(t, 32, len(id), "generated header")
+	assert.Equalf(t, id, body["request_id"], "success body")
+
+	w = httptest.NewRecorder()
+	r = httptest.NewRequest("GET", "/ok", nil)
+	r.Header.Set(render.RequestIDHeader, "req-2")
+	handler.ServeHTTP(w, r)
+	body = nil
+	assert.Nilf(t, json.Unmarshal(w.Body.Bytes(), &body), "unmarshal")
+	assert.Equalf(t, "req-2", body["request_id"], "success body with request id")
+	assert.Equalf(t, "req-2", w.Header().Get(render.RequestIDHeader), "success header")
+
+	rp := rd.NewResponse(nil, render.E(errors.NotFound), render.WithRequestID("explicit")).(*render.Response)
+	assert.Equalf(t, "explicit", rp.Body().(map[string]any)["request_id"], "explicit")
+	assert.Equalf(t, "explicit", rp.Header().Get(render.RequestIDHeader), "explicit header")
+}
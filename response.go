@@ -2,14 +2,11 @@ package render
 
 import (
 	"context"
-	"fmt"
-	"log"
 	"net/http"
 	"time"
 
 	"github.com/ccmonky/errors"
 	"github.com/ccmonky/inithook"
-	"go.uber.org/atomic"
 )
 
 var (
@@ -42,47 +39,53 @@ type ResponseInterface interface {
 // - MetaError: contributes the response meta from the error(include all values it carries), use `WithError`(E) to specify
 // - Extension: any kvs used to extend the `Response`, use `WithKV`(KV) to specify
 // - Template: used to specify the variant of `Response`, use `WithTemplate`(T) to specify
+// - ETag, LastModified: validators used for conditional GET, use `WithETag` and `WithLastModified` to specify
+// - Fields: the field violations of `ValidationError`, specified by `WithError` if the error is(or wraps) a `ValidationError`
+// - RequestID: the id used to correlate the response with the logs, use `WithRequestID` to specify, or extracted from request,
+// it's rendered as the `X-Request-Id` header and the `request_id` member of body, note that the generated ones make
+// the generated `ETag` vary per request, specify the `ETag` with `WithETag` for the cacheable responses
 //
 // See tests for more details.
 type Response struct {
 	errors.MetaError
-	Data      any
-	Extension map[any]any
-	Template  string
+	Data         any
+	Extension    map[any]any
+	Template     string
+	ETag         string
+	LastModified time.Time
+	Fields       []FieldViolation
+	RequestID    string
 
-	m map[string]any // NOTE: errors.Map(MetaError)
+	m        map[string]any // NOTE: errors.Map(MetaError)
+	renderer *Renderer      // NOTE: the renderer which created it, provides app metadata
+	exposure *Exposure      // NOTE: specified by `WithExposure` or request, see `Exposure`
+	incident string         // NOTE: the incident id of the hidden detail, see `Detail`
 }
 
 // NewResponse creates a new *Response instance and returns it or it's variant as `ResponseInterface`
 func NewResponse(data any, opts ...ResponseOption) ResponseInterface {
-	rp := &Response{
-		Data: data,
-	}
-	for _, opt := range opts {
-		opt(rp)
-	}
-	if rp.MetaError == nil {
-		rp.MetaError = errors.OK
-	}
-	transformer, err := Transformers.Get(context.Background(), rp.Template)
-	if err != nil {
-		log.Panicf("response transformer %s not found", rp.Template)
-	}
-	return transformer(rp)
+	return defaultRenderer.NewResponse(data, opts...)
 }
 
 // ResponseOption `Response` creation option func
 type ResponseOption func(*Response)
 
-// WithError used to specify `MetaError` of `Response`
+// WithError used to specify `MetaError` of `Response`, the non `MetaError` is wrapped as `errors.Unknown`,
+// except the `ValidationError`(see `AsValidationError`) which is wrapped as `ValidationFailed`
 func WithError(err error) ResponseOption {
 	return func(rp *Response) {
 		var me errors.MetaError
+		ve, isValidation := AsValidationError(err)
+		if isValidation {
+			rp.Fields = ve.Fields
+		}
 		if err == nil {
 			me = errors.OK
 		} else {
 			if merr, ok := err.(errors.MetaError); ok {
 				me = merr
+			} else if isValidation {
+				me = errors.WithError(err, ValidationFailed).(errors.MetaError)
 			} else {
 				me = errors.WithError(err, errors.Unknown).(errors.MetaError)
 			}
@@ -108,50 +111,110 @@ func WithTemplate(tmpl string) ResponseOption {
 	}
 }
 
+// WithETag used to specify the `ETag` header of `Response`
+func WithETag(etag string) ResponseOption {
+	return func(rp *Response) {
+		rp.ETag = etag
+	}
+}
+
+// WithLastModified used to specify the `Last-Modified` header of `Response`
+func WithLastModified(t time.Time) ResponseOption {
+	return func(rp *Response) {
+		rp.LastModified = t
+	}
+}
+
 // Status implement `ResponseInterface` as default
 func (rp *Response) Status() int {
 	return errors.StatusAttr.Get(rp.MetaError)
 }
 
-// Header implement `ResponseInterface` as default
+// Header implement `ResponseInterface` as default, the meta headers are sanitized(see `SanitizeHeaderValue`),
+// named by `Renderer.HeaderName` and switched off by `DisabledHeaders`
 func (rp *Response) Header() http.Header {
 	var header = make(http.Header, 6)
 	header.Set(TemplateHeader, rp.Template)
 
 	// configured values
-	header.Set("X-App", appName.Load())
-	header.Set("X-Version", appVersion.Load())
+	rd := rp.rendererOrDefault()
+	app, version := rp.app()
+	rd.setMetaHeader(header, rp.Template, MetaApp, app)
+	rd.setMetaHeader(header, rp.Template, MetaVersion, version)
 
 	// // error meta values
-	header.Set("X-Code", rp.MetaError.Code())
-	header.Set("X-Message", rp.MetaError.Message())
-	header.Set("X-Detail", fmt.Sprint(rp.MetaError))
+	rd.setMetaHeader(header, rp.Template, MetaCode, rp.MetaError.Code())
+	rd.setMetaHeader(header, rp.Template, MetaMessage, rp.MetaError.Message())
+	rd.setMetaHeader(header, rp.Template, MetaDetail, rp.Detail())
+	if rp.RequestID != "" {
+		rd.setMetaHeader(header, rp.Template, MetaRequestID, rp.RequestID)
+	}
+
+	// validators
+	if rp.ETag != "" {
+		header.Set(ETagHeader, rp.ETag)
+	}
+	if !rp.LastModified.IsZero() {
+		header.Set(LastModifiedHeader, rp.LastModified.UTC().Format(http.TimeFormat))
+	}
 	return header
 
 }
 
 // Body implement `ResponseInterface` as default
 func (rp *Response) Body() any {
-	return map[string]any{
+	app, version := rp.app()
+	body := map[string]any{
 		// configured values
-		"app":     appName.Load(),
-		"version": appVersion.Load(),
+		"app":     app,
+		"version": version,
 
 		// error meta values
 		"code":    rp.MetaError.Code(),
 		"message": rp.MetaError.Message(),
-		"detail":  fmt.Sprint(rp.MetaError),
+		"detail":  rp.Detail(),
 
 		// dynamic values
-		"timestamp": time.Now().Unix(),
+		"timestamp": rp.now().Unix(),
 
 		// biz values
 		"data": rp.Data,
 	}
+	if len(rp.Fields) > 0 {
+		body["fields"] = rp.Fields
+	}
+	if rp.RequestID != "" {
+		body["request_id"] = rp.RequestID
+	}
+	return body
+}
+
+// failed tells if rp carries an error
+func (rp *Response) failed() bool {
+	return rp.MetaError != nil && rp.MetaError.Code() != errors.OK.Code()
+}
+
+// rendererOrDefault returns the renderer which created rp, or the default renderer
+func (rp *Response) rendererOrDefault() *Renderer {
+	if rp.renderer != nil {
+		return rp.renderer
+	}
+	return defaultRenderer
+}
+
+// app returns the app name and version of the renderer which created rp, or the default renderer
+func (rp *Response) app() (name, version string) {
+	return rp.rendererOrDefault().App()
+}
+
+// now returns the current time of the renderer's clock
+func (rp *Response) now() time.Time {
+	return rp.rendererOrDefault().Clock()()
 }
 
 // Get used to get value specified by key from Response's Extension or error's values
-// if found, return the value and true, otherwise return nil and false
+// if found, return the value and true, otherwise return nil and false,
+// the error's values are filtered by the exposure of rp(see `ExposedAttrs`)
 func Get(rp *Response, key any) (any, bool) {
 	if value, ok := rp.Extension[key]; ok {
 		return value, true
@@ -160,7 +223,7 @@ func Get(rp *Response, key any) (any, bool) {
 		if rp.m == nil {
 			rp.m = errors.Map(rp.MetaError)
 		}
-		if value, ok := rp.m[sk]; ok {
+		if value, ok := rp.m[sk]; ok && rp.exposed(sk) {
 			return value, true
 		}
 	}
@@ -175,26 +238,19 @@ var Transformers = inithook.NewMap[string, ResponseTransformer]()
 
 func init() {
 	inithook.RegisterAttrSetter(inithook.AppName, "render", func(ctx context.Context, value string) error {
-		appName.Store(value)
+		defaultRenderer.appName.Store(value)
 		return nil
 	})
 	inithook.RegisterAttrSetter(inithook.Version, "render", func(ctx context.Context, value string) error {
-		appVersion.Store(value)
+		defaultRenderer.appVersion.Store(value)
 		return nil
 	})
-	ctx := context.Background()
-	Transformers.Register(ctx, "", selfResponseTransformer)
 }
 
 func selfResponseTransformer(rp *Response) ResponseInterface {
 	return rp
 }
 
-var (
-	appName    = atomic.NewString("")
-	appVersion = atomic.NewString("")
-)
-
 var (
 	_ ResponseInterface = (*Response)(nil)
 )
@@ -0,0 +1,127 @@
+//go:build go1.21
+
+package render
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+)
+
+// SampleEvery used to log only 1 of every n renders of the codes, if no codes specified, it applies to all 4xx codes
+// without their own sampling, e.g.
+//
+//	render.NewErrorLogger(logger, render.SampleEvery(100, errors.NotFound.Code()), render.SampleEvery(10))
+func SampleEvery(n int, codes ...string) Option {
+	return func(o any) {
+		if options, ok := o.(*errorLoggerOptions); ok {
+			if len(codes) == 0 {
+				options.ClientErrorEvery = n
+			}
+			for _, code := range codes {
+				options.CodeEvery[code] = n
+			}
+		}
+	}
+}
+
+type errorLoggerOptions struct {
+	ClientErrorEvery int
+	CodeEvery        map[string]int
+}
+
+// ErrorLogger is the `Observer` logs the rendered non OK `MetaError` with `log/slog`, the level is derived from status:
+// `slog.LevelError` for 5xx, `slog.LevelWarn` for 4xx, otherwise `slog.LevelInfo`, the attributes are code, status,
+// message, detail(the full error chain regardless of `Exposure`), method, path and request_id, e.g.
+//
+//	render.Observers.Register(ctx, "errorlog", render.NewErrorLogger(slog.Default()))
+type ErrorLogger struct {
+	logger  *slog.Logger
+	options errorLoggerOptions
+	mu      sync.Mutex
+	counts  map[string]int // NOTE: the number of renders of the sampled codes
+}
+
+// NewErrorLogger creates a new `ErrorLogger`, nil logger means `slog.Default()`
+func NewErrorLogger(logger *slog.Logger, opts ...Option) *ErrorLogger {
+	options := errorLoggerOptions{
+		CodeEvery: make(map[string]int),
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return &ErrorLogger{
+		logger:  logger,
+		options: options,
+		counts:  make(map[string]int),
+	}
+}
+
+// WithErrorLogger used to register the `ErrorLogger` as the `errorlog` observer, see `NewErrorLogger`
+func WithErrorLogger(logger *slog.Logger, opts ...Option) RendererOption {
+	return WithObserver("errorlog", NewErrorLogger(logger, opts...))
+}
+
+// ObserveRender implement `Observer`
+func (el *ErrorLogger) ObserveRender(event RenderEvent) {
+	rp := event.Response
+	if rp == nil || !rp.failed() {
+		return
+	}
+	status := rp.Status()
+	code := rp.MetaError.Code()
+	if !el.sampled(code, status) {
+		return
+	}
+	level := slog.LevelInfo
+	switch {
+	case status >= 500:
+		level = slog.LevelError
+	case status >= 400:
+		level = slog.LevelWarn
+	}
+	logger := el.logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	ctx := context.Background()
+	attrs := []slog.Attr{
+		slog.String("code", code),
+		slog.Int("status", status),
+		slog.String("message", rp.MetaError.Message()),
+		slog.String("detail", fmt.Sprint(rp.MetaError)),
+	}
+	requestID := rp.RequestID
+	if r := event.Request; r != nil {
+		ctx = r.Context()
+		attrs = append(attrs, slog.String("method", r.Method), slog.String("path", r.URL.Path))
+		if requestID == "" {
+			requestID, _ = DefaultRequestIDExtractor.RequestID(r)
+		}
+	}
+	if requestID != "" {
+		attrs = append(attrs, slog.String("request_id", requestID))
+	}
+	logger.LogAttrs(ctx, level, "render error", attrs...)
+}
+
+// sampled tells if the render of code should be logged
+func (el *ErrorLogger) sampled(code string, status int) bool {
+	every, ok := el.options.CodeEvery[code]
+	if !ok && status >= 400 && status < 500 {
+		every = el.options.ClientErrorEvery
+	}
+	if every <= 1 {
+		return true
+	}
+	el.mu.Lock()
+	defer el.mu.Unlock()
+	n := el.counts[code]
+	el.counts[code] = n + 1
+	return n%every == 0
+}
+
+var (
+	_ Observer = (*ErrorLogger)(nil)
+)
@@ -0,0 +1,63 @@
+//go:build go1.21
+
+package render_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ccmonky/errors"
+	"github.com/ccmonky/render"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	rd := render.New(render.WithErrorLogger(logger, render.SampleEvery(3, errors.NotFound.Code())))
+	records := func() []map[string]any {
+		var rs []map[string]any
+		for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+			if line == "" {
+				continue
+			}
+			var r map[string]any
+			assert.Nilf(t, json.Unmarshal([]byte(line), &r), "unmarshal %s", line)
+			rs = append(rs, r)
+		}
+		buf.Reset()
+		return rs
+	}
+
+	r := httptest.NewRequest("POST", "/users", nil)
+	r.Header.Set(render.RequestIDHeader, "req-1")
+	rd.Err(httptest.NewRecorder(), r, render.JSON, errors.WithError(errors.New("db down"), errors.Unknown))
+	rs := records()
+	assert.Equalf(t, 1, len(rs), "5xx logged")
+	assert.Equalf(t, "ERROR", rs[0]["level"], "5xx level")
+	assert.Equalf(t, "unknown(2)", rs[0]["code"], "code")
+	assert.Equalf(t, float64(500), rs[0]["status"], "status")
+	assert.Equalf(t, "unknown", rs[0]["message"], "message")
+	assert.Truef(t, strings.HasPrefix(rs[0]["detail"].(string), "db down"), "detail chain")
+	assert.Equalf(t, "POST", rs[0]["method"], "method")
+	assert.Equalf(t, "/users", rs[0]["path"], "path")
+	assert.Equalf(t, "req-1", rs[0]["request_id"], "request id")
+
+	rd.Err(httptest.NewRecorder(), r, render.JSON, errors.InvalidArgument)
+	rs = records()
+	assert.Equalf(t, 1, len(rs), "4xx logged")
+	assert.Equalf(t, "WARN", rs[0]["level"], "4xx level")
+
+	for i := 0; i < 6; i++ {
+		rd.Err(httptest.NewRecorder(), r, render.JSON, errors.NotFound)
+	}
+	assert.Equalf(t, 2, len(records()), "sampled 1 of every 3")
+
+	rd.OK(httptest.NewRecorder(), r, render.JSON, "data")
+	rd.Err(httptest.NewRecorder(), r, render.JSON, nil)
+	assert.Equalf(t, 0, len(records()), "ok not logged")
+}
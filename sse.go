@@ -0,0 +1,238 @@
+package render
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ccmonky/errors"
+)
+
+// LastEventIDHeader `Last-Event-ID` header name, sent by the browser when reconnecting
+const LastEventIDHeader = "Last-Event-ID"
+
+// Event defines the server-sent event, `Data` is encoded by the data render(see `SSEDataRender`)
+type Event struct {
+	Event string
+	ID    string
+	Retry time.Duration
+	Data  any
+}
+
+// ResumeFunc used to replay the events after the last event id when the client reconnects
+type ResumeFunc func(lastEventID string) ([]Event, error)
+
+// SSEDataRender used to specify the content type whose render encodes the event data, default to `JSON`
+func SSEDataRender(ct ContentType) Option {
+	return func(o any) {
+		if options, ok := o.(*sseOptions); ok {
+			options.DataRender = ct
+		}
+	}
+}
+
+// SSEHeartbeat used to specify the interval of heartbeat comments, 0 disables heartbeat, default to 15s
+func SSEHeartbeat(d time.Duration) Option {
+	return func(o any) {
+		if options, ok := o.(*sseOptions); ok {
+			options.Heartbeat = d
+		}
+	}
+}
+
+// SSEResume used to specify the hook called with `Last-Event-ID` of request(see `ForRequest`)
+func SSEResume(fn ResumeFunc) Option {
+	return func(o any) {
+		if options, ok := o.(*sseOptions); ok {
+			options.Resume = fn
+		}
+	}
+}
+
+type sseOptions struct {
+	DataRender ContentType
+	Heartbeat  time.Duration
+	Resume     ResumeFunc
+}
+
+// sseRender implement `Render` for server-sent events, data can be a channel of `Event`, `*Event`,
+// `error` or any other value(as event data), or a single one of them.
+//
+// The stream stops when the channel closed or the context(see `StreamContext`) done,
+// an `error` value is rendered as the terminal `error` event carrying code and message.
+type sseRender struct{}
+
+// Streaming implement `Streamer`
+func (r sseRender) Streaming() bool {
+	return true
+}
+
+// Render implement `Render`
+func (r sseRender) Render(w http.ResponseWriter, data any, opts ...Option) error {
+	stream := newStreamOptions(opts...)
+	options := sseOptions{
+		DataRender: JSON,
+		Heartbeat:  15 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	render, err := stream.Renderer.Renders.Get(stream.Context, options.DataRender)
+	if err != nil {
+		return errors.WithMessagef(err, "get sse data render failed for %v", options.DataRender)
+	}
+	header := w.Header()
+	if val := header[ContentTypeHeader]; len(val) == 0 {
+		header[ContentTypeHeader] = EventStream.Header()
+	}
+	header.Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+
+	f := newFlusher(w, stream)
+	defer f.Stop()
+	s := &sseStream{
+		w:       f,
+		render:  render,
+		flusher: f,
+	}
+	if options.Resume != nil && stream.Request != nil {
+		if id := stream.Request.Header.Get(LastEventIDHeader); id != "" {
+			events, err := options.Resume(id)
+			if err != nil {
+				return s.terminate(err)
+			}
+			for _, event := range events {
+				if err := s.write(event); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	ch, ok := recvChan(data)
+	if !ok {
+		return s.send(data)
+	}
+	return s.receive(stream.Context, ch, options.Heartbeat)
+}
+
+type sseStream struct {
+	w       io.Writer
+	render  Render
+	flusher *flusher
+}
+
+func (s *sseStream) receive(ctx context.Context, ch reflect.Value, heartbeat time.Duration) error {
+	cases := []reflect.SelectCase{
+		{Dir: reflect.SelectRecv, Chan: ch},
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())},
+	}
+	if heartbeat > 0 {
+		ticker := time.NewTicker(heartbeat)
+		defer ticker.Stop()
+		cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ticker.C)})
+	}
+	for {
+		chosen, value, ok := reflect.Select(cases)
+		switch {
+		case chosen == 1:
+			return ctx.Err()
+		case chosen == 2:
+			if _, err := io.WriteString(s.w, ": heartbeat\n\n"); err != nil {
+				return err
+			}
+			s.flusher.Flush()
+		case !ok:
+			s.flusher.Flush()
+			return nil
+		default:
+			if err := s.send(value.Interface()); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// send writes one value as event, an `error` terminates the stream
+func (s *sseStream) send(v any) error {
+	switch v := v.(type) {
+	case error:
+		return s.terminate(v)
+	case Event:
+		return s.write(v)
+	case *Event:
+		return s.write(*v)
+	default:
+		return s.write(Event{Data: v})
+	}
+}
+
+// terminate writes the `error` event carrying code and message, and returns err
+func (s *sseStream) terminate(err error) error {
+	rp := &Response{}
+	E(err)(rp)
+	data := map[string]any{
+		"code":    rp.MetaError.Code(),
+		"message": rp.MetaError.Message(),
+	}
+	if werr := s.write(Event{Event: "error", Data: data}); werr != nil {
+		return fmt.Errorf("%w; write error event failed: %v", err, werr)
+	}
+	return err
+}
+
+func (s *sseStream) write(event Event) error {
+	var buf bytes.Buffer
+	if event.Event != "" {
+		fmt.Fprintf(&buf, "event: %s\n", sseField(event.Event))
+	}
+	if event.ID != "" {
+		fmt.Fprintf(&buf, "id: %s\n", sseField(event.ID))
+	}
+	if event.Retry > 0 {
+		fmt.Fprintf(&buf, "retry: %s\n", strconv.FormatInt(event.Retry.Milliseconds(), 10))
+	}
+	if event.Data != nil {
+		bw := newBufferedWriter(discardHeaderWriter{})
+		err := s.render.Render(bw, event.Data)
+		if err != nil {
+			bw.release()
+			return err
+		}
+		for _, line := range strings.Split(strings.TrimRight(bw.buf.String(), "\n"), "\n") {
+			buf.WriteString("data: ")
+			buf.WriteString(strings.TrimSuffix(line, "\r"))
+			buf.WriteByte('\n')
+		}
+		bw.release()
+	}
+	buf.WriteByte('\n')
+	if _, err := s.w.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	// NOTE: flush each event, the clients expect live updates
+	s.flusher.Flush()
+	return nil
+}
+
+// sseField removes line breaks which are not allowed in event, id fields
+func sseField(s string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
+
+// discardHeaderWriter provides an empty header for rendering event data into buffer
+type discardHeaderWriter struct{}
+
+func (discardHeaderWriter) Header() http.Header         { return make(http.Header) }
+func (discardHeaderWriter) WriteHeader(int)             {}
+func (discardHeaderWriter) Write(p []byte) (int, error) { return len(p), nil }
+
+var (
+	_ Render   = sseRender{}
+	_ Streamer = sseRender{}
+)
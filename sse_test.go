@@ -0,0 +1,82 @@
+package render_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ccmonky/errors"
+	"github.com/ccmonky/render"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSSE(t *testing.T) {
+	ch := make(chan any, 4)
+	ch <- render.Event{Event: "progress", ID: "1", Retry: 3 * time.Second, Data: map[string]int{"percent": 50}}
+	ch <- &render.Event{ID: "2", Data: "done"}
+	ch <- 100
+	ch <- errors.NotFound
+	w := httptest.NewRecorder()
+	err := render.EventStream.Render(w, ch)
+	assert.Equalf(t, errors.NotFound, err, "terminal err")
+	assert.Equalf(t, 200, w.Code, "status")
+	assert.Equalf(t, string(render.EventStream), w.Header().Get("Content-Type"), "content-type")
+	assert.Equalf(t, "no-cache", w.Header().Get("Cache-Control"), "cache-control")
+	expect := "event: progress\nid: 1\nretry: 3000\ndata: {\"percent\":50}\n\n" +
+		"id: 2\ndata: \"done\"\n\n" +
+		"data: 100\n\n" +
+		"event: error\ndata: {\"code\":\"not_found(5)\",\"message\":\"not found\"}\n\n"
+	assert.Equalf(t, expect, w.Body.String(), "body")
+}
+
+func TestSSEResume(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	r := httptest.NewRequest("GET", "/", nil).WithContext(ctx)
+	r.Header.Set(render.LastEventIDHeader, "7")
+	resume := render.SSEResume(func(lastEventID string) ([]render.Event, error) {
+		return []render.Event{{ID: lastEventID + "+1", Data: 8}}, nil
+	})
+	ch := make(chan render.Event)
+	go func() {
+		ch <- render.Event{ID: "9", Data: 9}
+		cancel()
+	}()
+	w := httptest.NewRecorder()
+	err := render.EventStream.OK(w, r, ch, resume, render.SSEHeartbeat(time.Millisecond))
+	assert.Equalf(t, context.Canceled, err, "canceled")
+	assert.Containsf(t, w.Body.String(), "id: 7+1\ndata: 8\n\n", "resumed event")
+	assert.Containsf(t, w.Body.String(), "id: 9\ndata: 9\n\n", "event")
+	assert.NotContainsf(t, w.Body.String(), "event: error", "no error event")
+}
+
+type flushRecorder struct {
+	*httptest.ResponseRecorder
+	flushed chan string
+}
+
+func (fr *flushRecorder) Flush() {
+	fr.flushed <- fr.Body.String()
+}
+
+func TestSSEFlushEachEvent(t *testing.T) {
+	ch := make(chan any)
+	w := &flushRecorder{ResponseRecorder: httptest.NewRecorder(), flushed: make(chan string, 4)}
+	done := make(chan error)
+	go func() {
+		done <- render.EventStream.Render(w, ch, render.SSEHeartbeat(0))
+	}()
+	ch <- render.Event{Event: "progress", Data: 1}
+	select {
+	case body := <-w.flushed:
+		assert.Equalf(t, "event: progress\ndata: 1\n\n", body, "flushed single event")
+	case <-time.After(time.Second):
+		t.Fatal("single event not flushed")
+	}
+	close(ch)
+	assert.Nilf(t, <-done, "closed")
+	assert.Equalf(t, "", w.Header().Get("Connection"), "no hop-by-hop header")
+}
+
+var _ http.Flusher = (*flushRecorder)(nil)
@@ -0,0 +1,30 @@
+package std
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+)
+
+// generic converts data into the generic tree with json semantics(json tags and `json.Marshaler` respected),
+// the result is made up of map[string]any, []any, string, json.Number, bool and nil
+func generic(data any) (any, error) {
+	bs, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(bs))
+	dec.UseNumber()
+	var v any
+	err = dec.Decode(&v)
+	return v, err
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
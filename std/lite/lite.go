@@ -0,0 +1,27 @@
+// Package lite registers the lightweight `std.YAML`, `std.TOML` and `std.MsgPack` renders, which are not
+// registered by `std` itself as the formats are not supported by the standard library, e.g.
+//
+//	import (
+//		_ "github.com/ccmonky/render/std"
+//		_ "github.com/ccmonky/render/std/lite"
+//	)
+package lite
+
+import (
+	"context"
+	"log"
+
+	"github.com/ccmonky/errors"
+	"github.com/ccmonky/render"
+	"github.com/ccmonky/render/std"
+)
+
+func init() {
+	ctx := context.Background()
+	err := render.Renders.Register(ctx, render.YAML, std.YAML{})
+	err = errors.WithError(err, render.Renders.Register(ctx, render.TOML, std.TOML{}))
+	err = errors.WithError(err, render.Renders.Register(ctx, render.MSGPACK, std.MsgPack{}))
+	if err != nil {
+		log.Panicln(errors.GetAllErrors(err))
+	}
+}
@@ -0,0 +1,22 @@
+package lite_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ccmonky/render"
+	_ "github.com/ccmonky/render/std/lite"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLite(t *testing.T) {
+	for _, ct := range []render.ContentType{render.YAML, render.TOML, render.MSGPACK} {
+		assert.Truef(t, ct.Ready(), "%s registered", ct)
+	}
+	assert.Truef(t, render.XML.Ready(), "std registered")
+
+	w := httptest.NewRecorder()
+	err := render.YAML.Render(w, map[string]any{"a": 1})
+	assert.Nilf(t, err, "render err")
+	assert.Equalf(t, "a: 1\n", w.Body.String(), "body")
+}
@@ -0,0 +1,128 @@
+package std
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+
+	"github.com/ccmonky/render"
+)
+
+// MsgPack is a lightweight msgpack render, data is converted like json then encoded,
+// so binary and extension types are not used, use gin or other msgpack library for full support,
+// it is not registered by default, import `github.com/ccmonky/render/std/lite` to register it
+type MsgPack struct{}
+
+func (r MsgPack) Render(w http.ResponseWriter, data any, opts ...render.Option) error {
+	v, err := generic(data)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := writeMsgPack(&buf, v); err != nil {
+		return err
+	}
+	return write(w, render.MSGPACK, buf.Bytes())
+}
+
+func writeMsgPack(buf *bytes.Buffer, v any) error {
+	switch v := v.(type) {
+	case nil:
+		buf.WriteByte(0xc0)
+	case bool:
+		if v {
+			buf.WriteByte(0xc3)
+		} else {
+			buf.WriteByte(0xc2)
+		}
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			writeMsgPackInt(buf, i)
+			return nil
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return err
+		}
+		buf.WriteByte(0xcb)
+		binary.Write(buf, binary.BigEndian, math.Float64bits(f))
+	case string:
+		n := len(v)
+		switch {
+		case n < 32:
+			buf.WriteByte(0xa0 | byte(n))
+		case n <= math.MaxUint8:
+			buf.WriteByte(0xd9)
+			buf.WriteByte(byte(n))
+		case n <= math.MaxUint16:
+			buf.WriteByte(0xda)
+			binary.Write(buf, binary.BigEndian, uint16(n))
+		default:
+			buf.WriteByte(0xdb)
+			binary.Write(buf, binary.BigEndian, uint32(n))
+		}
+		buf.WriteString(v)
+	case []any:
+		writeMsgPackLen(buf, len(v), 0x90, 0xdc)
+		for _, item := range v {
+			if err := writeMsgPack(buf, item); err != nil {
+				return err
+			}
+		}
+	case map[string]any:
+		writeMsgPackLen(buf, len(v), 0x80, 0xde)
+		for _, k := range sortedKeys(v) {
+			if err := writeMsgPack(buf, k); err != nil {
+				return err
+			}
+			if err := writeMsgPack(buf, v[k]); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported msgpack value %T", v)
+	}
+	return nil
+}
+
+// writeMsgPackLen writes fix(len < 16), 16 or 32 bits array/map header
+func writeMsgPackLen(buf *bytes.Buffer, n int, fix, b16 byte) {
+	switch {
+	case n < 16:
+		buf.WriteByte(fix | byte(n))
+	case n <= math.MaxUint16:
+		buf.WriteByte(b16)
+		binary.Write(buf, binary.BigEndian, uint16(n))
+	default:
+		buf.WriteByte(b16 + 1)
+		binary.Write(buf, binary.BigEndian, uint32(n))
+	}
+}
+
+func writeMsgPackInt(buf *bytes.Buffer, i int64) {
+	switch {
+	case i >= 0 && i < 128:
+		buf.WriteByte(byte(i))
+	case i < 0 && i >= -32:
+		buf.WriteByte(byte(int8(i)))
+	case i >= math.MinInt8 && i <= math.MaxInt8:
+		buf.WriteByte(0xd0)
+		buf.WriteByte(byte(int8(i)))
+	case i >= math.MinInt16 && i <= math.MaxInt16:
+		buf.WriteByte(0xd1)
+		binary.Write(buf, binary.BigEndian, int16(i))
+	case i >= math.MinInt32 && i <= math.MaxInt32:
+		buf.WriteByte(0xd2)
+		binary.Write(buf, binary.BigEndian, int32(i))
+	default:
+		buf.WriteByte(0xd3)
+		binary.Write(buf, binary.BigEndian, i)
+	}
+}
+
+var (
+	_ render.Render = MsgPack{}
+)
@@ -0,0 +1,335 @@
+package std
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"html/template"
+	"io"
+	"log"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/ccmonky/errors"
+	"github.com/ccmonky/render"
+)
+
+func init() {
+	ctx := context.Background()
+	err := render.Renders.Register(ctx, render.XML, XML{})
+	err = errors.WithError(err, render.Renders.Register(ctx, render.Text, Text{}))
+	err = errors.WithError(err, render.Renders.Register(ctx, render.HTML, HTML{}))
+	err = errors.WithError(err, render.Renders.Register(ctx, render.XHTML, XHTML{}))
+	err = errors.WithError(err, render.Renders.Register(ctx, render.Binary, Binary{}))
+	err = errors.WithError(err, render.Renders.Register(ctx, render.JSONP, JSONP{}))
+	err = errors.WithError(err, render.Renders.Register(ctx, render.JSONASCII, ASCIIJSON{}))
+	if err != nil {
+		log.Panicln(errors.GetAllErrors(err))
+	}
+}
+
+// XML render data with encoding/xml, maps(e.g. `Response` body) are supported,
+// they are converted like json and their keys are encoded as child elements in lexical order
+type XML struct{}
+
+func (r XML) Render(w http.ResponseWriter, data any, opts ...render.Option) error {
+	options := xmlOptions{
+		Root: "response",
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if data != nil && reflect.TypeOf(data).Kind() == reflect.Map {
+		v, err := generic(data)
+		if err != nil {
+			return err
+		}
+		if m, ok := v.(map[string]any); ok {
+			data = xmlMap{name: options.Root, m: m}
+		}
+	}
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	enc.Indent(options.Prefix, options.Indent)
+	if err := enc.Encode(data); err != nil {
+		return err
+	}
+	return write(w, render.XML, buf.Bytes())
+}
+
+type xmlOptions struct {
+	Root           string
+	Prefix, Indent string
+}
+
+// XMLRoot specify the root element name of `map[string]any`, default to `response`
+func XMLRoot(root string) render.Option {
+	return func(o any) {
+		if options, ok := o.(*xmlOptions); ok {
+			options.Root = root
+		}
+	}
+}
+
+// XMLIndent specify the indent of xml
+func XMLIndent(prefix, indent string) render.Option {
+	return func(o any) {
+		if options, ok := o.(*xmlOptions); ok {
+			options.Prefix, options.Indent = prefix, indent
+		}
+	}
+}
+
+// xmlMap encodes map as element with sorted child elements
+type xmlMap struct {
+	name string
+	m    map[string]any
+}
+
+func (xm xmlMap) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start = xml.StartElement{Name: xml.Name{Local: xm.name}}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(xm.m))
+	for k := range xm.m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if err := encodeXMLElement(e, k, xm.m[k]); err != nil {
+			return err
+		}
+	}
+	return e.EncodeToken(start.End())
+}
+
+func encodeXMLElement(e *xml.Encoder, name string, v any) error {
+	switch v := v.(type) {
+	case nil:
+		return e.EncodeElement("", xml.StartElement{Name: xml.Name{Local: name}})
+	case map[string]any:
+		return e.Encode(xmlMap{name: name, m: v})
+	case []any:
+		for _, item := range v {
+			if err := encodeXMLElement(e, name, item); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return e.EncodeElement(v, xml.StartElement{Name: xml.Name{Local: name}})
+	}
+}
+
+// Text render data as plain text, string, []byte, `fmt.Stringer` and error are written as is,
+// if `TextFormat` specified, data([]any for multiple args) is formatted with it, otherwise `fmt.Sprint` is used
+type Text struct{}
+
+func (r Text) Render(w http.ResponseWriter, data any, opts ...render.Option) error {
+	options := textOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	var text string
+	switch v := data.(type) {
+	case []any:
+		if options.Format != "" {
+			text = fmt.Sprintf(options.Format, v...)
+		} else {
+			text = fmt.Sprint(v...)
+		}
+	case []byte:
+		text = string(v)
+	default:
+		if options.Format != "" {
+			text = fmt.Sprintf(options.Format, v)
+		} else {
+			text = fmt.Sprint(v)
+		}
+	}
+	return write(w, render.Text, []byte(text))
+}
+
+type textOptions struct {
+	Format string
+}
+
+// TextFormat specify the text format
+func TextFormat(format string) render.Option {
+	return func(o any) {
+		if options, ok := o.(*textOptions); ok {
+			options.Format = format
+		}
+	}
+}
+
+// HTML render data with html/template
+type HTML struct{}
+
+func (r HTML) Render(w http.ResponseWriter, data any, opts ...render.Option) error {
+	return renderHTML(w, render.HTML, data, opts...)
+}
+
+// XHTML render data with html/template like `HTML`, the template should produce well-formed xhtml
+type XHTML struct{}
+
+func (r XHTML) Render(w http.ResponseWriter, data any, opts ...render.Option) error {
+	return renderHTML(w, render.XHTML, data, opts...)
+}
+
+func renderHTML(w http.ResponseWriter, ct render.ContentType, data any, opts ...render.Option) error {
+	options := htmlOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.Template == nil {
+		return fmt.Errorf("std html template not specified")
+	}
+	var buf bytes.Buffer
+	var err error
+	if options.Name == "" {
+		err = options.Template.Execute(&buf, data)
+	} else {
+		err = options.Template.ExecuteTemplate(&buf, options.Name, data)
+	}
+	if err != nil {
+		return err
+	}
+	return write(w, ct, buf.Bytes())
+}
+
+type htmlOptions struct {
+	Template *template.Template
+	Name     string
+}
+
+// HTMLTemplate specify html template, it's also used by `XHTML`
+func HTMLTemplate(t *template.Template) render.Option {
+	return func(o any) {
+		if options, ok := o.(*htmlOptions); ok {
+			options.Template = t
+		}
+	}
+}
+
+// HTMLName specify html template name
+func HTMLName(name string) render.Option {
+	return func(o any) {
+		if options, ok := o.(*htmlOptions); ok {
+			options.Name = name
+		}
+	}
+}
+
+// Binary render []byte, string or `io.Reader` as is, other data is formatted with `%v`
+type Binary struct{}
+
+func (r Binary) Render(w http.ResponseWriter, data any, opts ...render.Option) error {
+	switch v := data.(type) {
+	case []byte:
+		return write(w, render.Binary, v)
+	case string:
+		return write(w, render.Binary, []byte(v))
+	case io.Reader:
+		setContentType(w, render.Binary)
+		_, err := io.Copy(w, v)
+		return err
+	default:
+		return write(w, render.Binary, []byte(fmt.Sprintf("%v", v)))
+	}
+}
+
+// JSONP render data as json wrapped by the callback, plain json if callback not specified
+type JSONP struct{}
+
+func (r JSONP) Render(w http.ResponseWriter, data any, opts ...render.Option) error {
+	options := jsonpOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	bs, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if options.Callback == "" {
+		return write(w, render.JSONP, bs)
+	}
+	if !callbackRegexp.MatchString(options.Callback) {
+		return fmt.Errorf("invalid jsonp callback %q", options.Callback)
+	}
+	var buf bytes.Buffer
+	buf.WriteString(options.Callback)
+	buf.WriteByte('(')
+	buf.Write(bs)
+	buf.WriteString(");")
+	return write(w, render.JSONP, buf.Bytes())
+}
+
+type jsonpOptions struct {
+	Callback string
+}
+
+// JSONPCallback specify the jsonp callback, must be a javascript identifier path, e.g. `jQuery.cb_1`
+func JSONPCallback(callback string) render.Option {
+	return func(o any) {
+		if options, ok := o.(*jsonpOptions); ok {
+			options.Callback = callback
+		}
+	}
+}
+
+var callbackRegexp = regexp.MustCompile(`^[a-zA-Z_$][0-9a-zA-Z_$]*(\.[a-zA-Z_$][0-9a-zA-Z_$]*)*$`)
+
+// ASCIIJSON render data as json with non-ASCII characters escaped
+type ASCIIJSON struct{}
+
+func (r ASCIIJSON) Render(w http.ResponseWriter, data any, opts ...render.Option) error {
+	bs, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	for len(bs) > 0 {
+		c, size := utf8.DecodeRune(bs)
+		if c < utf8.RuneSelf {
+			buf.WriteByte(bs[0])
+		} else if c > 0xFFFF {
+			c -= 0x10000
+			fmt.Fprintf(&buf, `\u%04x\u%04x`, 0xD800+(c>>10), 0xDC00+(c&0x3FF))
+		} else {
+			fmt.Fprintf(&buf, `\u%04x`, c)
+		}
+		bs = bs[size:]
+	}
+	return write(w, render.JSONASCII, buf.Bytes())
+}
+
+func setContentType(w http.ResponseWriter, ct render.ContentType) {
+	header := w.Header()
+	if val := header[render.ContentTypeHeader]; len(val) == 0 {
+		header[render.ContentTypeHeader] = ct.Header()
+	}
+}
+
+func write(w http.ResponseWriter, ct render.ContentType, data []byte) error {
+	setContentType(w, ct)
+	_, err := w.Write(data)
+	return err
+}
+
+var (
+	_ render.Render = XML{}
+	_ render.Render = Text{}
+	_ render.Render = HTML{}
+	_ render.Render = XHTML{}
+	_ render.Render = Binary{}
+	_ render.Render = JSONP{}
+	_ render.Render = ASCIIJSON{}
+)
@@ -0,0 +1,201 @@
+package std_test
+
+import (
+	"context"
+	"html/template"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ccmonky/errors"
+	"github.com/ccmonky/inithook"
+	"github.com/ccmonky/render"
+	"github.com/ccmonky/render/std"
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	err := inithook.ExecuteAttrSetters(context.Background(), inithook.AppName, "myapp")
+	if err != nil {
+		panic(err)
+	}
+	err = inithook.ExecuteAttrSetters(context.Background(), inithook.Version, "0.3.0")
+	if err != nil {
+		panic(err)
+	}
+	err = render.Transformers.Register(context.Background(), "no_timestamp", func(rp *render.Response) render.ResponseInterface {
+		return &NoTimestampResponse{rp}
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+type NoTimestampResponse struct {
+	*render.Response
+}
+
+func (ntr NoTimestampResponse) Body() any {
+	m := ntr.Response.Body().(map[string]any)
+	delete(m, "timestamp")
+	delete(m, "detail")
+	return m
+}
+
+func newResponse() render.ResponseInterface {
+	return render.NewResponse(map[string]any{
+		"one":    1,
+		"string": "string",
+		"list":   []any{map[string]any{"x": 1.5}, map[string]any{"x": true}},
+	}, render.E(errors.NotFound), render.T("no_timestamp"))
+}
+
+func TestXML(t *testing.T) {
+	w := httptest.NewRecorder()
+	err := render.XML.Render(w, newResponse())
+	assert.Nilf(t, err, "render err")
+	assert.Equalf(t, 404, w.Code, "status")
+	assert.Equalf(t, string(render.XML), w.Header().Get("Content-Type"), "content-type")
+	expect := `<?xml version="1.0" encoding="UTF-8"?>` + "\n" +
+		`<response><app>myapp</app><code>not_found(5)</code><data><list><x>1.5</x></list><list><x>true</x></list>` +
+		`<one>1</one><string>string</string></data><message>not found</message><version>0.3.0</version></response>`
+	assert.Equalf(t, expect, w.Body.String(), "body")
+
+	type item struct {
+		Name string `xml:"name,attr"`
+	}
+	w = httptest.NewRecorder()
+	err = render.XML.Render(w, item{Name: "a"})
+	assert.Nilf(t, err, "render err")
+	assert.Containsf(t, w.Body.String(), `<item name="a"></item>`, "struct body")
+}
+
+func TestText(t *testing.T) {
+	w := httptest.NewRecorder()
+	err := render.Text.Render(w, []any{"a", 1}, std.TextFormat("%s=%d"))
+	assert.Nilf(t, err, "render err")
+	assert.Equalf(t, string(render.Text), w.Header().Get("Content-Type"), "content-type")
+	assert.Equalf(t, "a=1", w.Body.String(), "body")
+
+	w = httptest.NewRecorder()
+	render.Text.Render(w, "plain")
+	assert.Equalf(t, "plain", w.Body.String(), "body")
+}
+
+func TestHTML(t *testing.T) {
+	tmpl := template.Must(template.New("page").Parse(`<p>{{.data.string}}</p>`))
+	w := httptest.NewRecorder()
+	err := render.HTML.Render(w, newResponse(), std.HTMLTemplate(tmpl), std.HTMLName("page"))
+	assert.Nilf(t, err, "render err")
+	assert.Equalf(t, 404, w.Code, "status")
+	assert.Equalf(t, "<p>string</p>", w.Body.String(), "body")
+
+	w = httptest.NewRecorder()
+	err = render.HTML.Render(w, newResponse())
+	assert.NotNilf(t, err, "template not specified")
+	assert.Equalf(t, 500, w.Code, "status")
+}
+
+func TestXHTML(t *testing.T) {
+	tmpl := template.Must(template.New("page").Parse(`<p>{{.data.string}}</p>`))
+	w := httptest.NewRecorder()
+	err := render.XHTML.Render(w, newResponse(), std.HTMLTemplate(tmpl))
+	assert.Nilf(t, err, "render err")
+	assert.Equalf(t, 404, w.Code, "status")
+	assert.Equalf(t, string(render.XHTML), w.Header().Get("Content-Type"), "content-type")
+	assert.Equalf(t, "<p>string</p>", w.Body.String(), "body")
+}
+
+func TestBinary(t *testing.T) {
+	w := httptest.NewRecorder()
+	err := render.Binary.Render(w, []byte{0, 1, 2})
+	assert.Nilf(t, err, "render err")
+	assert.Equalf(t, string(render.Binary), w.Header().Get("Content-Type"), "content-type")
+	assert.Equalf(t, []byte{0, 1, 2}, w.Body.Bytes(), "body")
+}
+
+func TestJSONP(t *testing.T) {
+	w := httptest.NewRecorder()
+	err := render.JSONP.Render(w, map[string]int{"one": 1}, std.JSONPCallback("jQuery.cb_1"))
+	assert.Nilf(t, err, "render err")
+	assert.Equalf(t, string(render.JSONP), w.Header().Get("Content-Type"), "content-type")
+	assert.Equalf(t, `jQuery.cb_1({"one":1});`, w.Body.String(), "body")
+
+	w = httptest.NewRecorder()
+	err = render.JSONP.Render(w, 1, std.JSONPCallback("alert(1)//"))
+	assert.NotNilf(t, err, "invalid callback")
+}
+
+func TestASCIIJSON(t *testing.T) {
+	w := httptest.NewRecorder()
+	err := render.JSONASCII.Render(w, map[string]string{"lang": "中文😀"})
+	assert.Nilf(t, err, "render err")
+	assert.Equalf(t, string(render.JSONASCII), w.Header().Get("Content-Type"), "content-type")
+	assert.Equalf(t, `{"lang":"\u4e2d\u6587\ud83d\ude00"}`, w.Body.String(), "body")
+}
+
+func TestYAML(t *testing.T) {
+	assert.Falsef(t, render.YAML.Ready(), "not registered by default")
+	rd := render.New(render.WithRender(render.YAML, std.YAML{}))
+	w := httptest.NewRecorder()
+	err := rd.Render(w, render.YAML, newResponse())
+	assert.Nilf(t, err, "render err")
+	assert.Equalf(t, string(render.YAML), w.Header().Get("Content-Type"), "content-type")
+	expect := `app: "myapp"
+code: "not_found(5)"
+data:
+  list:
+    -
+      x: 1.5
+    -
+      x: true
+  one: 1
+  string: "string"
+message: "not found"
+version: "0.3.0"
+`
+	assert.Equalf(t, expect, w.Body.String(), "body")
+}
+
+func TestTOML(t *testing.T) {
+	assert.Falsef(t, render.TOML.Ready(), "not registered by default")
+	rd := render.New(render.WithRender(render.TOML, std.TOML{}))
+	w := httptest.NewRecorder()
+	err := rd.Render(w, render.TOML, newResponse())
+	assert.Nilf(t, err, "render err")
+	assert.Equalf(t, string(render.TOML), w.Header().Get("Content-Type"), "content-type")
+	expect := `app = "myapp"
+code = "not_found(5)"
+message = "not found"
+version = "0.3.0"
+
+[data]
+one = 1
+string = "string"
+
+[[data.list]]
+x = 1.5
+
+[[data.list]]
+x = true
+`
+	assert.Equalf(t, expect, w.Body.String(), "body")
+
+	w = httptest.NewRecorder()
+	err = rd.Render(w, render.TOML, []int{1})
+	assert.NotNilf(t, err, "root not table")
+}
+
+func TestMsgPack(t *testing.T) {
+	assert.Falsef(t, render.MSGPACK.Ready(), "not registered by default")
+	rd := render.New(render.WithRender(render.MSGPACK, std.MsgPack{}))
+	w := httptest.NewRecorder()
+	err := rd.Render(w, render.MSGPACK, map[string]any{"a": []any{1, -1, 300, "s", nil, true, 1.5}})
+	assert.Nilf(t, err, "render err")
+	assert.Equalf(t, string(render.MSGPACK), w.Header().Get("Content-Type"), "content-type")
+	expect := []byte{
+		0x81, 0xa1, 'a',
+		0x97, 0x01, 0xff, 0xd1, 0x01, 0x2c, 0xa1, 's', 0xc0, 0xc3,
+		0xcb, 0x3f, 0xf8, 0, 0, 0, 0, 0, 0,
+	}
+	assert.Equalf(t, expect, w.Body.Bytes(), "body")
+}
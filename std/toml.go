@@ -0,0 +1,167 @@
+package std
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/ccmonky/render"
+)
+
+// TOML is a lightweight toml render, data is converted like json, the root must be a table,
+// nil values are omitted as toml has no null, use gin or other toml library for full toml support,
+// it is not registered by default, import `github.com/ccmonky/render/std/lite` to register it
+type TOML struct{}
+
+func (r TOML) Render(w http.ResponseWriter, data any, opts ...render.Option) error {
+	v, err := generic(data)
+	if err != nil {
+		return err
+	}
+	m, ok := v.(map[string]any)
+	if !ok {
+		return fmt.Errorf("toml root should be a table, but got %T", data)
+	}
+	var buf bytes.Buffer
+	if err := writeTOMLTable(&buf, nil, m); err != nil {
+		return err
+	}
+	return write(w, render.TOML, buf.Bytes())
+}
+
+// writeTOMLTable writes the key/values first, then the sub tables and arrays of tables
+func writeTOMLTable(buf *bytes.Buffer, path []string, m map[string]any) error {
+	keys := sortedKeys(m)
+	for _, k := range keys {
+		switch v := m[k].(type) {
+		case nil, map[string]any:
+			continue
+		case []any:
+			if isTableArray(v) {
+				continue
+			}
+		}
+		value, err := tomlValue(m[k])
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(buf, "%s = %s\n", tomlKey(k), value)
+	}
+	for _, k := range keys {
+		sub := append(append([]string{}, path...), k)
+		switch v := m[k].(type) {
+		case map[string]any:
+			fmt.Fprintf(buf, "\n[%s]\n", tomlPath(sub))
+			if err := writeTOMLTable(buf, sub, v); err != nil {
+				return err
+			}
+		case []any:
+			if !isTableArray(v) {
+				continue
+			}
+			for _, item := range v {
+				fmt.Fprintf(buf, "\n[[%s]]\n", tomlPath(sub))
+				if err := writeTOMLTable(buf, sub, item.(map[string]any)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func isTableArray(v []any) bool {
+	if len(v) == 0 {
+		return false
+	}
+	for _, item := range v {
+		if _, ok := item.(map[string]any); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// tomlValue returns the inline value
+func tomlValue(v any) (string, error) {
+	switch v := v.(type) {
+	case bool:
+		return strconv.FormatBool(v), nil
+	case json.Number:
+		return v.String(), nil
+	case string:
+		return tomlString(v), nil
+	case []any:
+		var buf bytes.Buffer
+		buf.WriteByte('[')
+		for i, item := range v {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			s, err := tomlValue(item)
+			if err != nil {
+				return "", err
+			}
+			buf.WriteString(s)
+		}
+		buf.WriteByte(']')
+		return buf.String(), nil
+	case map[string]any:
+		var buf bytes.Buffer
+		buf.WriteByte('{')
+		i := 0
+		for _, k := range sortedKeys(v) {
+			if v[k] == nil {
+				continue
+			}
+			s, err := tomlValue(v[k])
+			if err != nil {
+				return "", err
+			}
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			buf.WriteString(tomlKey(k) + " = " + s)
+			i++
+		}
+		buf.WriteByte('}')
+		return buf.String(), nil
+	default:
+		return "", fmt.Errorf("unsupported toml value %T", v)
+	}
+}
+
+func tomlString(s string) string {
+	// NOTE: json escapes are valid toml basic string escapes except `\/`, which json.Marshal never emits
+	bs, _ := json.Marshal(s)
+	return string(bs)
+}
+
+func tomlKey(k string) string {
+	for _, c := range k {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-') {
+			return tomlString(k)
+		}
+	}
+	if k == "" {
+		return `""`
+	}
+	return k
+}
+
+func tomlPath(path []string) string {
+	var buf bytes.Buffer
+	for i, k := range path {
+		if i > 0 {
+			buf.WriteByte('.')
+		}
+		buf.WriteString(tomlKey(k))
+	}
+	return buf.String()
+}
+
+var (
+	_ render.Render = TOML{}
+)
@@ -0,0 +1,110 @@
+package std
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ccmonky/render"
+)
+
+// YAML is a lightweight yaml render, data is converted like json then emitted in block style,
+// strings are always double quoted, use gin or other yaml library for full yaml support,
+// it is not registered by default, import `github.com/ccmonky/render/std/lite` to register it
+type YAML struct{}
+
+func (r YAML) Render(w http.ResponseWriter, data any, opts ...render.Option) error {
+	v, err := generic(data)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	writeYAML(&buf, v, 0)
+	return write(w, render.YAML, buf.Bytes())
+}
+
+func writeYAML(buf *bytes.Buffer, v any, indent int) {
+	pad := strings.Repeat("  ", indent)
+	switch v := v.(type) {
+	case map[string]any:
+		if len(v) == 0 {
+			buf.WriteString(pad + "{}\n")
+			return
+		}
+		for _, k := range sortedKeys(v) {
+			buf.WriteString(pad + yamlKey(k) + ":")
+			writeYAMLChild(buf, v[k], indent)
+		}
+	case []any:
+		if len(v) == 0 {
+			buf.WriteString(pad + "[]\n")
+			return
+		}
+		for _, item := range v {
+			buf.WriteString(pad + "-")
+			writeYAMLChild(buf, item, indent)
+		}
+	default:
+		buf.WriteString(pad + yamlScalar(v) + "\n")
+	}
+}
+
+func writeYAMLChild(buf *bytes.Buffer, v any, indent int) {
+	switch c := v.(type) {
+	case map[string]any:
+		if len(c) > 0 {
+			buf.WriteByte('\n')
+			writeYAML(buf, c, indent+1)
+			return
+		}
+		buf.WriteString(" {}\n")
+	case []any:
+		if len(c) > 0 {
+			buf.WriteByte('\n')
+			writeYAML(buf, c, indent+1)
+			return
+		}
+		buf.WriteString(" []\n")
+	default:
+		buf.WriteString(" " + yamlScalar(c) + "\n")
+	}
+}
+
+// yamlKey returns the plain key if it's an identifier which can not be resolved as other types
+func yamlKey(k string) string {
+	if !yamlKeyRegexp.MatchString(k) {
+		return yamlScalar(k)
+	}
+	switch strings.ToLower(k) {
+	case "true", "false", "yes", "no", "on", "off", "y", "n", "null":
+		return yamlScalar(k)
+	}
+	return k
+}
+
+var yamlKeyRegexp = regexp.MustCompile(`^[a-zA-Z_][0-9a-zA-Z_-]*$`)
+
+func yamlScalar(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(v)
+	case json.Number:
+		return v.String()
+	case string:
+		// NOTE: json string is a valid yaml double-quoted scalar
+		bs, _ := json.Marshal(v)
+		return string(bs)
+	default:
+		bs, _ := json.Marshal(v)
+		return string(bs)
+	}
+}
+
+var (
+	_ render.Render = YAML{}
+)
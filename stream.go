@@ -0,0 +1,183 @@
+package render
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// StreamContext used to specify the context of streaming render, the stream stops when it's done
+func StreamContext(ctx context.Context) Option {
+	return func(o any) {
+		if options, ok := o.(*streamOptions); ok {
+			options.Context = ctx
+		}
+	}
+}
+
+// StreamRequest used to specify the request of streaming render, the request context is used as `StreamContext`.
+//
+// Deprecated: use `ForRequest`, which makes all the request aware renders aware of the request.
+func StreamRequest(r *http.Request) Option {
+	return ForRequest(r)
+}
+
+// FlushEvery used to specify how many records are written between two flushes of streaming render
+func FlushEvery(n int) Option {
+	return func(o any) {
+		if options, ok := o.(*streamOptions); ok {
+			options.FlushEvery = n
+		}
+	}
+}
+
+// FlushInterval used to specify the max interval between two flushes of streaming render
+func FlushInterval(d time.Duration) Option {
+	return func(o any) {
+		if options, ok := o.(*streamOptions); ok {
+			options.FlushInterval = d
+		}
+	}
+}
+
+type streamOptions struct {
+	Context       context.Context
+	Request       *http.Request
+	FlushEvery    int
+	FlushInterval time.Duration
+	Renderer      *Renderer
+}
+
+func (options *streamOptions) setRequest(r *http.Request) {
+	options.Request = r
+	options.Context = r.Context()
+}
+
+func (options *streamOptions) setRenderer(rd *Renderer) {
+	options.Renderer = rd
+}
+
+func newStreamOptions(opts ...Option) *streamOptions {
+	options := &streamOptions{
+		Context:       context.Background(),
+		FlushEvery:    100,
+		FlushInterval: time.Second,
+		Renderer:      defaultRenderer,
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options
+}
+
+// flusher writes to the writer and flushes it every n records, the pending records are also flushed
+// every interval by a background ticker until `Stop` is called, so all the writes must go through it
+type flusher struct {
+	mu      sync.Mutex
+	w       http.ResponseWriter
+	every   int
+	pending int
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+func newFlusher(w http.ResponseWriter, options *streamOptions) *flusher {
+	f := &flusher{
+		w:     w,
+		every: options.FlushEvery,
+	}
+	if options.FlushInterval > 0 {
+		f.stop, f.done = make(chan struct{}), make(chan struct{})
+		go f.run(options.FlushInterval)
+	}
+	return f
+}
+
+func (f *flusher) run(interval time.Duration) {
+	defer close(f.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-f.stop:
+			return
+		case <-ticker.C:
+			f.mu.Lock()
+			if f.pending > 0 {
+				f.flush()
+			}
+			f.mu.Unlock()
+		}
+	}
+}
+
+// Write implement `io.Writer`, it's serialized with the interval flushes
+func (f *flusher) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.w.Write(p)
+}
+
+// Tick records a written record and flushes if needed
+func (f *flusher) Tick() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.pending++
+	if f.every > 0 && f.pending >= f.every {
+		f.flush()
+	}
+}
+
+// Flush flushes the writer if it implement `http.Flusher`
+func (f *flusher) Flush() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.flush()
+}
+
+// Stop stops the interval flushes, it must be called when the stream closes
+func (f *flusher) Stop() {
+	if f.stop != nil {
+		close(f.stop)
+		<-f.done
+		f.stop = nil
+	}
+}
+
+func (f *flusher) flush() {
+	if fl, ok := f.w.(http.Flusher); ok {
+		fl.Flush()
+	}
+	f.pending = 0
+}
+
+// recvChan returns the reflect value of data if it's a receivable channel
+func recvChan(data any) (reflect.Value, bool) {
+	v := reflect.ValueOf(data)
+	if v.Kind() == reflect.Chan && v.Type().ChanDir()&reflect.RecvDir != 0 {
+		return v, true
+	}
+	return v, false
+}
+
+// receive calls fn for each value received from ch until ch closed, ctx done or fn returns error
+func receive(ctx context.Context, ch reflect.Value, fn func(any) error) error {
+	cases := []reflect.SelectCase{
+		{Dir: reflect.SelectRecv, Chan: ch},
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())},
+	}
+	for {
+		chosen, value, ok := reflect.Select(cases)
+		if chosen == 1 {
+			return ctx.Err()
+		}
+		if !ok {
+			return nil
+		}
+		if err := fn(value.Interface()); err != nil {
+			return err
+		}
+	}
+}
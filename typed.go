@@ -0,0 +1,98 @@
+package render
+
+import (
+	"encoding/xml"
+
+	"github.com/ccmonky/errors"
+)
+
+// TypedBody is the body of `TypedResponse`, it has the same members as the `Response` body,
+// but in stable field order and with typed data, so the schema can be derived from it
+type TypedBody[T any] struct {
+	XMLName   xml.Name         `json:"-" xml:"response" yaml:"-"`
+	App       string           `json:"app" xml:"app" yaml:"app"`
+	Version   string           `json:"version" xml:"version" yaml:"version"`
+	Code      string           `json:"code" xml:"code" yaml:"code"`
+	Message   string           `json:"message" xml:"message" yaml:"message"`
+	Detail    string           `json:"detail" xml:"detail" yaml:"detail"`
+	Timestamp int64            `json:"timestamp" xml:"timestamp" yaml:"timestamp"`
+	Data      T                `json:"data" xml:"data" yaml:"data"`
+	Fields    []FieldViolation `json:"fields,omitempty" xml:"fields>field,omitempty" yaml:"fields,omitempty"`
+	RequestID string           `json:"request_id,omitempty" xml:"request_id,omitempty" yaml:"request_id,omitempty"`
+}
+
+// TypedResponse is the `Response` variant whose body is `TypedBody[T]`, the body is built once and reused, e.g.
+//
+//	render.JSON.Render(w, render.NewTypedResponse(user, render.E(err)))
+//
+// use `TypedTransformer` to make it as the variant of a template.
+type TypedResponse[T any] struct {
+	*Response
+	data T
+	body *TypedBody[T]
+}
+
+// NewTypedResponse creates a new `TypedResponse` with data, the template specified by `WithTemplate` is only used as header
+func NewTypedResponse[T any](data T, opts ...ResponseOption) *TypedResponse[T] {
+	rp := &Response{
+		Data:     data,
+		renderer: defaultRenderer,
+	}
+	for _, opt := range opts {
+		opt(rp)
+	}
+	return newTypedResponse(rp, data)
+}
+
+// TypedTransformer returns the `ResponseTransformer` which transforms `*Response` to `TypedResponse[T]`,
+// the zero value is used if the data of `Response` is nil, and the untyped `*Response` is returned
+// if the data is not T, e.g.
+//
+//	render.Transformers.Register(ctx, "user", render.TypedTransformer[User]())
+func TypedTransformer[T any]() ResponseTransformer {
+	return func(rp *Response) ResponseInterface {
+		data, ok := rp.Data.(T)
+		if !ok && rp.Data != nil {
+			return selfResponseTransformer(rp)
+		}
+		return newTypedResponse(rp, data)
+	}
+}
+
+func newTypedResponse[T any](rp *Response, data T) *TypedResponse[T] {
+	if rp.MetaError == nil {
+		rp.MetaError = errors.OK
+	}
+	return &TypedResponse[T]{
+		Response: rp,
+		data:     data,
+	}
+}
+
+// Body implement `ResponseInterface`, returns the `*TypedBody[T]`
+func (tr *TypedResponse[T]) Body() any {
+	return tr.TypedBody()
+}
+
+// TypedBody returns the body, it's built at the first call
+func (tr *TypedResponse[T]) TypedBody() *TypedBody[T] {
+	if tr.body == nil {
+		app, version := tr.app()
+		tr.body = &TypedBody[T]{
+			App:       app,
+			Version:   version,
+			Code:      tr.MetaError.Code(),
+			Message:   tr.MetaError.Message(),
+			Detail:    tr.Detail(),
+			Timestamp: tr.now().Unix(),
+			Data:      tr.data,
+			Fields:    tr.Fields,
+			RequestID: tr.RequestID,
+		}
+	}
+	return tr.body
+}
+
+var (
+	_ ResponseInterface = (*TypedResponse[any])(nil)
+)
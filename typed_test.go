@@ -0,0 +1,59 @@
+package render_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/ccmonky/errors"
+	"github.com/ccmonky/render"
+	"github.com/stretchr/testify/assert"
+)
+
+type typedUser struct {
+	Name string `json:"name"`
+	Age  int    `json:"age"`
+}
+
+func TestTypedResponse(t *testing.T) {
+	rp := render.NewTypedResponse(typedUser{Name: "a", Age: 1})
+	body := rp.TypedBody()
+	assert.Equalf(t, typedUser{Name: "a", Age: 1}, body.Data, "data")
+	assert.Equalf(t, "success(0)", body.Code, "code")
+	assert.Equalf(t, 200, rp.Status(), "status")
+	assert.Truef(t, body == rp.Body(), "body reused")
+
+	w := httptest.NewRecorder()
+	err := render.JSON.Render(w, render.NewTypedResponse(typedUser{Name: "b"}, render.E(errors.NotFound)))
+	assert.Nilf(t, err, "render err")
+	assert.Equalf(t, 404, w.Code, "status")
+	assert.Equalf(t, "not_found(5)", w.Header().Get("X-Code"), "x-code")
+	expect := `{"app":"myapp","version":"0.3.0","code":"not_found(5)","message":"not found","detail":"meta={source=errors;code=not_found(5)}:status={404}","timestamp":0,"data":{"name":"b","age":0}}`
+	got := regexp.MustCompile(`"timestamp":\d+`).ReplaceAllString(w.Body.String(), `"timestamp":0`)
+	assert.Equalf(t, expect, got, "field order")
+
+	rp = render.NewTypedResponse(typedUser{}, render.E(render.NewValidationError().Add("name", "required", "name is required")))
+	assert.Equalf(t, 422, rp.Status(), "validation status")
+	assert.Equalf(t, 1, len(rp.TypedBody().Fields), "validation fields")
+}
+
+func TestTypedTransformer(t *testing.T) {
+	err := render.Transformers.Register(context.Background(), "typed_user", render.TypedTransformer[typedUser]())
+	assert.Nilf(t, err, "register")
+	rp := render.NewResponse(typedUser{Name: "c"}, render.T("typed_user"))
+	tr, ok := rp.(*render.TypedResponse[typedUser])
+	assert.Truef(t, ok, "typed")
+	assert.Equalf(t, "c", tr.TypedBody().Data.Name, "data")
+	assert.Equalf(t, "typed_user", rp.Header().Get("X-Render-Template"), "template")
+
+	rp = render.NewResponse(nil, render.T("typed_user"), render.E(errors.NotFound))
+	tr, ok = rp.(*render.TypedResponse[typedUser])
+	assert.Truef(t, ok, "nil data typed")
+	assert.Equalf(t, typedUser{}, tr.TypedBody().Data, "nil data zero value")
+
+	rp = render.NewResponse("not a user", render.T("typed_user"))
+	_, ok = rp.(*render.Response)
+	assert.Truef(t, ok, "mismatched data untyped")
+	assert.Equalf(t, "not a user", rp.Body().(map[string]any)["data"], "mismatched data kept")
+}
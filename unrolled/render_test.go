@@ -78,14 +78,14 @@ func TestUnrolled(t *testing.T) {
 	w := newResponseWriter()
 	render.JSON.Render(w, nil)
 	assert.Equalf(t, 200, w.status, "status")
-	assert.Equalf(t, 1, len(w.header), "header")
+	assert.Equalf(t, 2, len(w.header), "header")
 	assert.Containsf(t, w.header, "Content-Type", "content-type header")
 	assert.Equalf(t, "null", w.body.String(), "body")
 
 	w = newResponseWriter()
 	render.JSON.Render(w, 101)
 	assert.Equalf(t, 200, w.status, "status")
-	assert.Equalf(t, 1, len(w.header), "header")
+	assert.Equalf(t, 2, len(w.header), "header")
 	assert.Equalf(t, "101", w.body.String(), "body")
 
 	w = newResponseWriter()
@@ -94,7 +94,7 @@ func TestUnrolled(t *testing.T) {
 		"string": "string",
 	})
 	assert.Equalf(t, 200, w.status, "status")
-	assert.Equalf(t, 1, len(w.header), "header")
+	assert.Equalf(t, 2, len(w.header), "header")
 	assert.JSONEq(t, `{"one":1,"string":"string"}`, w.body.String(), "body")
 
 	w = newResponseWriter()
@@ -103,7 +103,7 @@ func TestUnrolled(t *testing.T) {
 		"string": "string",
 	}, render.E(errors.NotFound), render.T("no_timestamp")))
 	assert.Equalf(t, 404, w.status, "status")
-	assert.Equalf(t, 7, len(w.header), "header")
+	assert.Equalf(t, 8, len(w.header), "header")
 	body := `{
 		"app": "myapp",
 		"code": "not_found(5)",
@@ -0,0 +1,165 @@
+package render
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/ccmonky/errors"
+	"go.uber.org/atomic"
+)
+
+// FieldViolation describes one field which failed the validation
+type FieldViolation struct {
+	Field   string `json:"field" xml:"field" yaml:"field"`
+	Rule    string `json:"rule" xml:"rule" yaml:"rule"`
+	Message string `json:"message" xml:"message" yaml:"message"`
+}
+
+// ValidationError aggregates the field violations, it's rendered with status 422(see `ValidationFailed`),
+// and the violations are rendered as the `fields` member of `Response` body(`errors` member of problem details), e.g.
+//
+//	ve := render.NewValidationError()
+//	if u.Name == "" {
+//		ve.Add("name", "required", "name is required")
+//	}
+//	if err := ve.Err(); err != nil {
+//		render.JSON.Err(w, r, err)
+//	}
+type ValidationError struct {
+	Fields []FieldViolation
+}
+
+// NewValidationError creates a new `ValidationError` with violations
+func NewValidationError(violations ...FieldViolation) *ValidationError {
+	return &ValidationError{
+		Fields: violations,
+	}
+}
+
+// Add appends a field violation
+func (ve *ValidationError) Add(field, rule, message string) *ValidationError {
+	ve.Fields = append(ve.Fields, FieldViolation{
+		Field:   field,
+		Rule:    rule,
+		Message: message,
+	})
+	return ve
+}
+
+// Err returns nil if no violation, otherwise returns ve
+func (ve *ValidationError) Err() error {
+	if len(ve.Fields) == 0 {
+		return nil
+	}
+	return ve
+}
+
+// Error implement `error`
+func (ve *ValidationError) Error() string {
+	items := make([]string, 0, len(ve.Fields))
+	for _, f := range ve.Fields {
+		items = append(items, f.Field+": "+f.Message)
+	}
+	return "validation failed: " + strings.Join(items, "; ")
+}
+
+// FieldError is the adapter interface of struct-tag validators' field error,
+// e.g. `validator.FieldError` of github.com/go-playground/validator satisfies it
+type FieldError interface {
+	// Namespace returns the field path, e.g. `User.Addresses[0].City`
+	Namespace() string
+
+	// Tag returns the validation rule, e.g. `required`
+	Tag() string
+
+	// Error returns the violation message
+	Error() string
+}
+
+// Validator validates the value decoded by `Bind`, the returned error can be a `ValidationError`,
+// a `FieldError` or a slice of `FieldError`(e.g. `validator.ValidationErrors`), e.g.
+//
+//	validate := validator.New()
+//	render.SetValidator(render.ValidatorFunc(validate.Struct))
+type Validator interface {
+	Validate(v any) error
+}
+
+// ValidatorFunc defines the function that implement Validator
+type ValidatorFunc func(v any) error
+
+func (vf ValidatorFunc) Validate(v any) error {
+	return vf(v)
+}
+
+// SetValidator sets the validator used by `Bind` after decoding, nil to disable
+func SetValidator(v Validator) {
+	validator.Store(validatorHolder{v})
+}
+
+// AsValidationError converts err to `ValidationError` if err is(or wraps) a `ValidationError`,
+// or err is a `FieldError` or a slice of `FieldError`
+func AsValidationError(err error) (*ValidationError, bool) {
+	if err == nil {
+		return nil, false
+	}
+	var ve *ValidationError
+	if errors.As(err, &ve) {
+		return ve, true
+	}
+	if fe, ok := err.(FieldError); ok {
+		return NewValidationError(fieldViolation(fe)), true
+	}
+	rv := reflect.ValueOf(err)
+	if rv.Kind() != reflect.Slice || rv.Len() == 0 {
+		return nil, false
+	}
+	ve = NewValidationError()
+	for i := 0; i < rv.Len(); i++ {
+		fe, ok := rv.Index(i).Interface().(FieldError)
+		if !ok {
+			return nil, false
+		}
+		ve.Fields = append(ve.Fields, fieldViolation(fe))
+	}
+	return ve, true
+}
+
+func fieldViolation(fe FieldError) FieldViolation {
+	return FieldViolation{
+		Field:   fe.Namespace(),
+		Rule:    fe.Tag(),
+		Message: fe.Error(),
+	}
+}
+
+// validate validates v with the validator set by `SetValidator`
+func validate(v any) error {
+	holder, _ := validator.Load().(validatorHolder)
+	if holder.Validator == nil {
+		return nil
+	}
+	err := holder.Validate(v)
+	if err == nil {
+		return nil
+	}
+	if _, ok := err.(errors.MetaError); ok {
+		return err
+	}
+	if ve, ok := AsValidationError(err); ok {
+		return errors.WithError(ve, ValidationFailed)
+	}
+	return errors.WithError(err, ValidationFailed)
+}
+
+// validatorHolder makes nil validator storable by `atomic.Value`
+type validatorHolder struct {
+	Validator
+}
+
+var validator = &atomic.Value{}
+
+var (
+	_ error     = (*ValidationError)(nil)
+	_ Validator = (*ValidatorFunc)(nil)
+)
@@ -0,0 +1,92 @@
+package render_test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ccmonky/errors"
+	"github.com/ccmonky/render"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeFieldError struct {
+	ns, tag string
+}
+
+func (fe fakeFieldError) Namespace() string { return fe.ns }
+func (fe fakeFieldError) Tag() string       { return fe.tag }
+func (fe fakeFieldError) Error() string     { return fe.ns + " failed on " + fe.tag }
+
+type fakeFieldErrors []fakeFieldError
+
+func (fes fakeFieldErrors) Error() string { return "fake field errors" }
+
+func TestValidationError(t *testing.T) {
+	ve := render.NewValidationError()
+	assert.Nilf(t, ve.Err(), "empty")
+	ve.Add("name", "required", "name is required").Add("age", "min", "age must be at least 18")
+	assert.Equalf(t, "validation failed: name: name is required; age: age must be at least 18", ve.Error(), "error")
+
+	rp := render.NewResponse(nil, render.E(ve.Err()))
+	assert.Equalf(t, 422, rp.Status(), "status")
+	assert.Equalf(t, "validation_failed", rp.Header().Get("X-Code"), "code")
+	body := rp.Body().(map[string]any)
+	assert.Equalf(t, ve.Fields, body["fields"], "fields")
+
+	rp = render.NewResponse(nil, render.E(errors.NotFound))
+	_, ok := rp.Body().(map[string]any)["fields"]
+	assert.Falsef(t, ok, "no fields")
+
+	w := httptest.NewRecorder()
+	render.ProblemJSON.Render(w, render.NewResponse(nil, render.E(ve), render.T(render.ProblemTemplate)))
+	assert.Equalf(t, 422, w.Code, "problem status")
+	var problem map[string]any
+	err := json.Unmarshal(w.Body.Bytes(), &problem)
+	assert.Nilf(t, err, "problem unmarshal")
+	assert.Equalf(t, []any{
+		map[string]any{"field": "name", "rule": "required", "message": "name is required"},
+		map[string]any{"field": "age", "rule": "min", "message": "age must be at least 18"},
+	}, problem["errors"], "problem errors")
+}
+
+func TestAsValidationError(t *testing.T) {
+	_, ok := render.AsValidationError(errors.New("xxx"))
+	assert.Falsef(t, ok, "plain error")
+
+	ve, ok := render.AsValidationError(fakeFieldErrors{{"User.Name", "required"}, {"User.Age", "gte"}})
+	assert.Truef(t, ok, "field errors")
+	assert.Equalf(t, []render.FieldViolation{
+		{Field: "User.Name", Rule: "required", Message: "User.Name failed on required"},
+		{Field: "User.Age", Rule: "gte", Message: "User.Age failed on gte"},
+	}, ve.Fields, "field errors")
+
+	ve, ok = render.AsValidationError(fakeFieldError{"User.Name", "required"})
+	assert.Truef(t, ok, "field error")
+	assert.Equalf(t, 1, len(ve.Fields), "field error")
+}
+
+func TestBindValidate(t *testing.T) {
+	render.SetValidator(render.ValidatorFunc(func(v any) error {
+		if v.(*bindUser).Name == "" {
+			return fakeFieldErrors{{"bindUser.Name", "required"}}
+		}
+		return nil
+	}))
+	defer render.SetValidator(nil)
+
+	r := httptest.NewRequest("POST", "/", strings.NewReader(`{"age":1}`))
+	r.Header.Set(render.ContentTypeHeader, "application/json")
+	var u bindUser
+	err := render.Bind(r, &u)
+	assert.Truef(t, errors.Is(err, render.ValidationFailed), "validation failed")
+	rp := render.NewResponse(nil, render.E(err))
+	assert.Equalf(t, 422, rp.Status(), "status")
+	assert.Equalf(t, []render.FieldViolation{{Field: "bindUser.Name", Rule: "required", Message: "bindUser.Name failed on required"}}, rp.(*render.Response).Fields, "fields")
+
+	r = httptest.NewRequest("POST", "/", strings.NewReader(`{"name":"a"}`))
+	r.Header.Set(render.ContentTypeHeader, "application/json")
+	err = render.Bind(r, &u)
+	assert.Nilf(t, err, "valid")
+}
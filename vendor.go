@@ -0,0 +1,107 @@
+package render
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ccmonky/inithook"
+)
+
+// VendorTypes used to store the mapping of the vendor media type to template, the key is the vendor media type
+// without structured suffix, optionally with `version` and `profile` parameters, e.g.
+//
+//	render.VendorTypes.Register(ctx, "application/vnd.acme.v2", "v2")
+//	render.VendorTypes.Register(ctx, "application/vnd.acme; version=3", "v3")
+//	render.VendorTypes.Register(ctx, "application/vnd.acme; profile=compact", "compact")
+//
+// then `Accept: application/vnd.acme.v2+json` negotiates `application/vnd.acme.v2+json`, which is rendered by
+// the base render of the suffix(`+json` by `JSON`, `+xml` by `XML`, `+yaml` by `YAML`) with the `v2` template,
+// and echoed as the `Content-Type`. If several vendor types are acceptable with the same quality, the first one
+// in lexical order wins.
+var VendorTypes = inithook.NewMap[string, string]()
+
+// VendorParams the parameters of vendor media type used to select the template
+var VendorParams = []string{"version", "profile"}
+
+// suffixRenders the base renders of structured suffixes in server preference order
+var suffixRenders = []struct {
+	suffix string
+	base   ContentType
+}{
+	{"json", JSON},
+	{"xml", XML},
+	{"yaml", YAML},
+}
+
+// vendorType returns the template and base render of the vendor media type
+func (rd *Renderer) vendorType(ctx context.Context, ct ContentType) (tmpl string, base ContentType, ok bool) {
+	mt := ParseMediaType(string(ct))
+	i := strings.LastIndexByte(mt.Subtype, '+')
+	if i < 0 {
+		return "", "", false
+	}
+	subtype, suffix := mt.Subtype[:i], mt.Subtype[i+1:]
+	for _, sr := range suffixRenders {
+		if sr.suffix == suffix {
+			base, ok = sr.base, true
+		}
+	}
+	if !ok || !rd.Renders.Has(ctx, base) {
+		return "", "", false
+	}
+	vendorTypes := rd.VendorTypes.Map(ctx)
+	keys := make([]string, 0, len(vendorTypes))
+	for key := range vendorTypes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys) // NOTE: the first one in lexical order wins the ties
+	best := -1
+	for _, key := range keys {
+		vt := ParseMediaType(key)
+		if vt.Type != mt.Type || vt.Subtype != subtype {
+			continue
+		}
+		matched := 0
+		for _, p := range VendorParams {
+			if v, has := vt.Params[p]; has {
+				if !strings.EqualFold(mt.Params[p], v) {
+					matched = -1
+					break
+				}
+				matched++
+			}
+		}
+		if matched > best {
+			best, tmpl = matched, vendorTypes[key]
+		}
+	}
+	if best < 0 {
+		return "", "", false
+	}
+	return tmpl, base, true
+}
+
+// vendorContentTypes returns the concrete vendor media types with suffixes of registered base renders
+func (rd *Renderer) vendorContentTypes(ctx context.Context) []ContentType {
+	keys := rd.VendorTypes.Keys(ctx)
+	sort.Strings(keys)
+	var cts []ContentType
+	for _, key := range keys {
+		vt := ParseMediaType(key)
+		for _, sr := range suffixRenders {
+			if !rd.Renders.Has(ctx, sr.base) {
+				continue
+			}
+			ct := vt.String() + "+" + sr.suffix
+			for _, p := range VendorParams {
+				if v, ok := vt.Params[p]; ok {
+					ct += fmt.Sprintf("; %s=%s", p, v)
+				}
+			}
+			cts = append(cts, ContentType(ct))
+		}
+	}
+	return cts
+}
@@ -0,0 +1,75 @@
+package render_test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ccmonky/render"
+	"github.com/stretchr/testify/assert"
+)
+
+type versionedResponse struct {
+	*render.Response
+	version string
+}
+
+func (vr versionedResponse) Body() any {
+	return map[string]any{"version": vr.version, "data": vr.Data}
+}
+
+func TestVendorTypes(t *testing.T) {
+	transformer := func(version string) render.ResponseTransformer {
+		return func(rp *render.Response) render.ResponseInterface {
+			return versionedResponse{rp, version}
+		}
+	}
+	rd := render.New(
+		render.WithVendorType("application/vnd.acme.v2", "v2"),
+		render.WithVendorType("application/vnd.acme; version=3", "v3"),
+		render.WithTransformer("v2", transformer("v2")),
+		render.WithTransformer("v3", transformer("v3")),
+	)
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Accept", "application/vnd.acme.v2+json")
+	ct, err := rd.NegotiateE(r)
+	assert.Nilf(t, err, "v2 negotiate")
+	assert.Equalf(t, render.ContentType("application/vnd.acme.v2+json"), ct, "v2 content type")
+	assert.Equalf(t, "v2", rd.Template(r, ct), "v2 template")
+	w := httptest.NewRecorder()
+	err = rd.OK(w, r, ct, 1)
+	assert.Nilf(t, err, "v2 render")
+	assert.Equalf(t, "application/vnd.acme.v2+json", w.Header().Get("Content-Type"), "v2 echo")
+	assert.Equalf(t, "v2", w.Header().Get("X-Render-Template"), "v2 template header")
+	assert.JSONEqf(t, `{"version":"v2","data":1}`, w.Body.String(), "v2 body")
+
+	r.Header.Set("Accept", "application/vnd.acme+json; version=3, application/json; q=0.5")
+	ct = rd.Negotiate(r)
+	assert.Equalf(t, render.ContentType("application/vnd.acme+json; version=3"), ct, "v3 content type")
+	w = httptest.NewRecorder()
+	rd.OK(w, r, ct, 1)
+	assert.Equalf(t, "application/vnd.acme+json; version=3", w.Header().Get("Content-Type"), "v3 echo")
+	assert.JSONEqf(t, `{"version":"v3","data":1}`, w.Body.String(), "v3 body")
+
+	r.Header.Set("Accept", "application/vnd.acme+xml; version=3")
+	assert.Equalf(t, render.JSON, rd.Negotiate(r), "xml base not registered")
+	assert.Falsef(t, rd.Ready("application/vnd.acme+xml; version=3"), "xml not ready")
+
+	r.Header.Set("Accept", "*/*")
+	assert.Equalf(t, render.JSON, rd.Negotiate(r), "wildcard prefers builtin")
+
+	r.Header.Set("Accept", "application/vnd.acme.v2+json")
+	r.Header.Set(render.TemplateHeader, "v3")
+	assert.Equalf(t, "v3", rd.Template(r, rd.Negotiate(r)), "template header wins")
+}
+
+func TestVendorTypesTie(t *testing.T) {
+	rd := render.New(
+		render.WithVendorType("application/vnd.tie; version=1", "version"),
+		render.WithVendorType("application/vnd.tie; profile=compact", "profile"),
+	)
+	r := httptest.NewRequest("GET", "/", nil)
+	for i := 0; i < 20; i++ {
+		assert.Equalf(t, "profile", rd.Template(r, "application/vnd.tie+json; version=1; profile=compact"), "first in lexical order")
+	}
+}